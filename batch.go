@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/zinrai/json-subset/subset"
+)
+
+// batchPair is one comparison listed in a batch manifest
+type batchPair struct {
+	Subset   string `json:"subset"`
+	Superset string `json:"superset"`
+}
+
+// loadManifest reads the pairs of a batch manifest. A .csv manifest has one
+// "subset,superset" row per pair and may start with that header; anything
+// else is a JSON array of {"subset": ..., "superset": ...} objects.
+// Relative file names are resolved against the manifest's directory.
+func loadManifest(filename string) ([]batchPair, error) {
+	data, err := readInput(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []batchPair
+	if strings.EqualFold(filepath.Ext(filename), ".csv") {
+		records, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+		if err != nil {
+			return nil, &parseError{err}
+		}
+		for i, record := range records {
+			if len(record) != 2 {
+				return nil, &parseError{fmt.Errorf("line %d: want 2 columns, got %d", i+1, len(record))}
+			}
+			if i == 0 && record[0] == "subset" && record[1] == "superset" {
+				continue
+			}
+			pairs = append(pairs, batchPair{Subset: record[0], Superset: record[1]})
+		}
+	} else if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, &parseError{err}
+	}
+
+	names := []string{filename}
+	for _, p := range pairs {
+		names = append(names, p.Subset, p.Superset)
+	}
+	if err := checkStdinOnce(names...); err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(filename)
+	for i, p := range pairs {
+		if p.Subset == "" || p.Superset == "" {
+			return nil, &parseError{fmt.Errorf("pair %d: subset and superset are required", i+1)}
+		}
+		pairs[i].Subset = resolveManifestPath(dir, p.Subset)
+		pairs[i].Superset = resolveManifestPath(dir, p.Superset)
+	}
+	return pairs, nil
+}
+
+func resolveManifestPath(dir, name string) string {
+	if name == "-" || strings.HasPrefix(name, literalPrefix) || isURL(name) || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
+
+// runBatch compares every pair in a manifest, printing PASS or FAIL for
+// each and a summary at the end. A pair that cannot be loaded is reported
+// and the remaining pairs still run. In quiet mode only errors are printed.
+func runBatch(manifest string, in inputOptions, at string, opts subset.Options, formatOpts subset.FormatOptions, quiet bool, stdout, stderr io.Writer) int {
+	report, reportErr := stdout, stderr
+	if quiet {
+		report, reportErr = io.Discard, io.Discard
+	}
+
+	pairs, err := loadManifest(manifest)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading %s: %v\n", manifest, err)
+		return loadExitCode(err)
+	}
+
+	passed, failed, errored := 0, 0, 0
+	for _, p := range pairs {
+		name := p.Subset + " vs " + p.Superset
+		subsetData, supersetData, err := loadPair(p, in, at)
+		if err != nil {
+			errored++
+			fmt.Fprintf(stderr, "ERROR: %s: %v\n", name, err)
+			continue
+		}
+
+		isSubset, diffs := subset.CheckSubsetWithOptions(subsetData, supersetData, opts)
+		if isSubset {
+			passed++
+			fmt.Fprintf(report, "PASS: %s\n", name)
+			continue
+		}
+
+		failed++
+		fmt.Fprintf(reportErr, "FAIL: %s\n", name)
+		fmt.Fprint(reportErr, subset.FormatDiffOutputWithOptions(subsetData, diffs, formatOpts))
+		fmt.Fprintln(reportErr, subset.FormatDiffSummary(diffs))
+	}
+
+	summary := fmt.Sprintf("%d passed, %d failed", passed, failed)
+	if errored > 0 {
+		summary += fmt.Sprintf(", %d errors", errored)
+	}
+	fmt.Fprintln(reportErr, summary)
+
+	switch {
+	case errored > 0:
+		return exitError
+	case failed > 0:
+		return exitFailure
+	default:
+		return exitSuccess
+	}
+}
+
+// loadPair loads both documents of a batch pair
+func loadPair(p batchPair, in inputOptions, at string) (interface{}, interface{}, error) {
+	subsetData, err := loadInput(p.Subset, in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading %s: %w", p.Subset, err)
+	}
+	supersetData, err := loadInput(p.Superset, in)
+	if err != nil {
+		return nil, nil, fmt.Errorf("loading %s: %w", p.Superset, err)
+	}
+	if at != "" {
+		if supersetData, err = subset.SelectNode(supersetData, at); err != nil {
+			return nil, nil, fmt.Errorf("selecting --at in %s: %w", p.Superset, err)
+		}
+	}
+	return subsetData, supersetData, nil
+}
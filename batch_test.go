@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunBatch(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a_subset.json":   `{"name": "alice"}`,
+		"a_superset.json": `{"name": "alice", "age": 30}`,
+		"b_subset.json":   `{"name": "bob"}`,
+		"b_superset.json": `{"name": "carol"}`,
+	} {
+		writeFileIn(t, dir, name, content)
+	}
+
+	manifests := map[string]string{
+		"manifest.json": `[
+			{"subset": "a_subset.json", "superset": "a_superset.json"},
+			{"subset": "b_subset.json", "superset": "b_superset.json"}
+		]`,
+		"manifest.csv": "subset,superset\na_subset.json,a_superset.json\nb_subset.json,b_superset.json\n",
+	}
+	for name, content := range manifests {
+		t.Run(name, func(t *testing.T) {
+			manifest := writeFileIn(t, dir, name, content)
+
+			var stdout, stderr bytes.Buffer
+			if code := run([]string{"--batch", manifest}, &stdout, &stderr); code != exitFailure {
+				t.Fatalf("run() = %d, want %d; stderr: %s", code, exitFailure, stderr.String())
+			}
+			if want := "PASS: " + filepath.Join(dir, "a_subset.json"); !strings.Contains(stdout.String(), want) {
+				t.Errorf("stdout = %q, want %q", stdout.String(), want)
+			}
+			for _, want := range []string{"FAIL: " + filepath.Join(dir, "b_subset.json"), `"name": "bob"`, "1 passed, 1 failed"} {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("stderr = %q, want %q", stderr.String(), want)
+				}
+			}
+		})
+	}
+
+	missing := writeFileIn(t, dir, "missing.json", `[{"subset": "a_subset.json", "superset": "nope.json"}]`)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--batch", missing}, &stdout, &stderr); code != exitError {
+		t.Errorf("run() = %d, want %d", code, exitError)
+	}
+	if !strings.Contains(stderr.String(), "0 passed, 0 failed, 1 errors") {
+		t.Errorf("stderr = %q, want the error counted", stderr.String())
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import "fmt"
+
+// Keys of a --combined file holding both documents
+const (
+	combinedSubsetKey   = "subset"
+	combinedSupersetKey = "superset"
+)
+
+// loadCombined reads a file of the form {"subset": ..., "superset": ...}
+// for --combined. It returns the names the two documents are reported
+// under, and a copy of in that serves them from memory under those names.
+// Other keys, such as a description of the case, are ignored.
+func loadCombined(file string, in inputOptions) (subsetName, supersetName string, _ inputOptions, _ error) {
+	doc, err := loadInput(file, in)
+	if err != nil {
+		return "", "", in, err
+	}
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return "", "", in, &parseError{fmt.Errorf("combined file must be an object with %q and %q keys", combinedSubsetKey, combinedSupersetKey)}
+	}
+	for _, key := range []string{combinedSubsetKey, combinedSupersetKey} {
+		if _, ok := obj[key]; !ok {
+			return "", "", in, &parseError{fmt.Errorf("combined file has no %q key", key)}
+		}
+	}
+
+	subsetName, supersetName = file+"#"+combinedSubsetKey, file+"#"+combinedSupersetKey
+	in.documents = map[string]interface{}{
+		subsetName:   obj[combinedSubsetKey],
+		supersetName: obj[combinedSupersetKey],
+	}
+	return subsetName, supersetName, in, nil
+}
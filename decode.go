@@ -0,0 +1,502 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/theory/jsonpath/spec"
+	"gopkg.in/yaml.v3"
+)
+
+// gzipMagic is the header every gzip stream starts with
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// readCloser pairs a reader with the Close of the underlying input
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// decompress returns r unchanged unless it starts with the gzip magic
+// bytes, in which case reads are decompressed. Closing the result closes r.
+func decompress(r io.ReadCloser) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		r.Close()
+		return nil, err
+	}
+	if !bytes.Equal(header, gzipMagic) {
+		return readCloser{br, r}, nil
+	}
+	zr, err := gzip.NewReader(br)
+	if err != nil {
+		r.Close()
+		return nil, &parseError{err}
+	}
+	return readCloser{zr, r}, nil
+}
+
+// detectFormat guesses the input format from the file extension, looking
+// through a trailing .gz
+func detectFormat(filename string) string {
+	filename = strings.TrimSuffix(strings.ToLower(filename), ".gz")
+	switch filepath.Ext(filename) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".jsonc":
+		return "jsonc"
+	case ".xml":
+		return "xml"
+	default:
+		return "json"
+	}
+}
+
+// stripJSONC turns JSON with comments into strict JSON by replacing line
+// and block comments and trailing commas with spaces. Newlines are kept, so
+// line numbers and offsets stay the same. Comment markers inside strings
+// are left alone.
+func stripJSONC(data []byte) ([]byte, error) {
+	out := bytes.Clone(data)
+	blank := func(from, to int) {
+		for i := from; i < to; i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	// lastComma is the offset of a comma that may turn out to be trailing
+	lastComma := -1
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case c == '"':
+			lastComma = -1
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			end := bytes.IndexByte(out[i:], '\n')
+			if end < 0 {
+				end = len(out) - i
+			}
+			blank(i, i+end)
+			i += end - 1
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, &parseError{fmt.Errorf("unterminated comment at offset %d", i)}
+			}
+			blank(i, i+end+4)
+			i += end + 3
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			lastComma = -1
+		}
+	}
+	return out, nil
+}
+
+// decodeYAML decodes a YAML document into the same shape json.Unmarshal produces
+func decodeYAML(data []byte) (interface{}, error) {
+	var result interface{}
+	if err := yaml.Unmarshal(data, &result); err != nil {
+		return nil, &parseError{err}
+	}
+	normalized, err := normalizeYAML(result)
+	if err != nil {
+		return nil, &parseError{err}
+	}
+	if err := checkFinite(normalized, spec.NormalizedPath{}); err != nil {
+		return nil, &parseError{err}
+	}
+	return normalized, nil
+}
+
+// normalizeYAML converts YAML values into string-keyed maps, slices and
+// numbers. Integers become json.Number so those beyond 2^53 stay exact.
+func normalizeYAML(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			normalized, err := normalizeYAML(elem)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = normalized
+		}
+		return result, nil
+
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			keyStr, ok := key.(string)
+			if !ok {
+				keyStr = fmt.Sprint(key)
+			}
+			normalized, err := normalizeYAML(elem)
+			if err != nil {
+				return nil, err
+			}
+			result[keyStr] = normalized
+		}
+		return result, nil
+
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			normalized, err := normalizeYAML(elem)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = normalized
+		}
+		return result, nil
+
+	case int:
+		return json.Number(strconv.Itoa(v)), nil
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	case uint64:
+		return json.Number(strconv.FormatUint(v, 10)), nil
+	case float64:
+		return v, nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case string, bool, nil:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported YAML value of type %T", value)
+	}
+}
+
+// decodeTOML decodes a TOML document into the same shape json.Unmarshal produces
+func decodeTOML(data []byte) (interface{}, error) {
+	var result map[string]interface{}
+	if err := toml.Unmarshal(data, &result); err != nil {
+		return nil, &parseError{err}
+	}
+	normalized, err := normalizeTOML(result)
+	if err != nil {
+		return nil, &parseError{err}
+	}
+	if err := checkFinite(normalized, spec.NormalizedPath{}); err != nil {
+		return nil, &parseError{err}
+	}
+	return normalized, nil
+}
+
+// normalizeTOML converts TOML integers into json.Number, keeping them exact,
+// and datetimes into strings. Local dates and times keep their TOML spelling.
+func normalizeTOML(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			normalized, err := normalizeTOML(elem)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = normalized
+		}
+		return result, nil
+
+	case []map[string]interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			normalized, err := normalizeTOML(elem)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = normalized
+		}
+		return result, nil
+
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, elem := range v {
+			normalized, err := normalizeTOML(elem)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = normalized
+		}
+		return result, nil
+
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10)), nil
+	case float64:
+		return v, nil
+	case time.Time:
+		// The decoder marks local values with these zone names.
+		switch v.Location().String() {
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05.999999999"), nil
+		case "date-local":
+			return v.Format("2006-01-02"), nil
+		case "time-local":
+			return v.Format("15:04:05.999999999"), nil
+		}
+		return v.Format(time.RFC3339Nano), nil
+	case string, bool:
+		return v, nil
+	default:
+		return nil, fmt.Errorf("unsupported TOML value of type %T", value)
+	}
+}
+
+// checkFinite rejects NaN and infinite numbers, which YAML and TOML allow
+// but JSON cannot represent and which make numeric comparison ill-defined
+func checkFinite(value interface{}, path spec.NormalizedPath) error {
+	switch v := value.(type) {
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("non-finite number %v at %s", v, path)
+		}
+	case map[string]interface{}:
+		for key, elem := range v {
+			if err := checkFinite(elem, append(path[:len(path):len(path)], spec.Name(key))); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for i, elem := range v {
+			if err := checkFinite(elem, append(path[:len(path):len(path)], spec.Index(i))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// xmlMapping controls how XML is turned into JSON values
+type xmlMapping struct {
+	// attrPrefix is prepended to attribute names to make their keys
+	attrPrefix string
+	// textKey holds the text of an element that also has attributes or children
+	textKey string
+}
+
+// defaultXMLMapping is the documented convention, e.g. {"@id": "1", "#text": "x"}
+var defaultXMLMapping = xmlMapping{attrPrefix: "@", textKey: "#text"}
+
+// decodeXML decodes an XML document into the same shape json.Unmarshal
+// produces. The root element becomes an object with a single key. An
+// element with neither attributes nor child elements becomes its text; any
+// other element becomes an object holding its attributes, its children
+// (an array when a name repeats) and its text. Text is trimmed, values are
+// always strings, and namespace prefixes are dropped.
+func decodeXML(data []byte, m xmlMapping) (interface{}, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var root map[string]interface{}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, &parseError{err}
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if root != nil {
+				return nil, &parseError{fmt.Errorf("more than one root element, found <%s> after <%s>", t.Name.Local, firstKey(root))}
+			}
+			value, err := decodeXMLElement(dec, t, m)
+			if err != nil {
+				return nil, &parseError{err}
+			}
+			root = map[string]interface{}{t.Name.Local: value}
+		case xml.CharData:
+			if len(bytes.TrimSpace(t)) > 0 {
+				return nil, &parseError{fmt.Errorf("text outside the root element at offset %d", dec.InputOffset())}
+			}
+		}
+	}
+	if root == nil {
+		return nil, &parseError{errors.New("no root element")}
+	}
+	return root, nil
+}
+
+func decodeXMLElement(dec *xml.Decoder, start xml.StartElement, m xmlMapping) (interface{}, error) {
+	obj := make(map[string]interface{})
+	for _, attr := range start.Attr {
+		// Namespace declarations say nothing about the content.
+		if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		obj[m.attrPrefix+attr.Name.Local] = attr.Value
+	}
+
+	var text bytes.Buffer
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := decodeXMLElement(dec, t, m)
+			if err != nil {
+				return nil, err
+			}
+			// Element values are strings or objects, so an array can only
+			// come from an earlier repetition.
+			switch existing := obj[t.Name.Local].(type) {
+			case nil:
+				obj[t.Name.Local] = child
+			case []interface{}:
+				obj[t.Name.Local] = append(existing, child)
+			default:
+				obj[t.Name.Local] = []interface{}{existing, child}
+			}
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			content := strings.TrimSpace(text.String())
+			if len(obj) == 0 {
+				return content, nil
+			}
+			if content != "" {
+				obj[m.textKey] = content
+			}
+			return obj, nil
+		}
+	}
+}
+
+// firstKey returns the only key of a single-key object
+func firstKey(obj map[string]interface{}) string {
+	for key := range obj {
+		return key
+	}
+	return ""
+}
+
+// jsonVisitor receives what walkJSON finds in a JSON document. Either
+// function may be nil.
+type jsonVisitor struct {
+	// value is called with the path and starting byte offset of every value
+	value func(path spec.NormalizedPath, start int)
+	// key is called for each key of an object, in document order
+	key func(object spec.NormalizedPath, key string) error
+}
+
+// walkJSON visits the values of valid JSON data in document order
+func walkJSON(data []byte, v jsonVisitor) error {
+	return walkJSONValue(json.NewDecoder(bytes.NewReader(data)), data, spec.NormalizedPath{}, v)
+}
+
+func walkJSONValue(dec *json.Decoder, data []byte, path spec.NormalizedPath, v jsonVisitor) error {
+	if v.value != nil {
+		// InputOffset is the end of the previous token; the value starts after
+		// any whitespace and the ':' or ',' separating it from that token.
+		start := int(dec.InputOffset())
+		for start < len(data) && bytes.IndexByte([]byte(" \t\r\n:,"), data[start]) >= 0 {
+			start++
+		}
+		v.value(path, start)
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		return nil
+	}
+
+	switch delim {
+	case '{':
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := tok.(string)
+			if v.key != nil {
+				if err := v.key(path, key); err != nil {
+					return err
+				}
+			}
+			if err := walkJSONValue(dec, data, append(path[:len(path):len(path)], spec.Name(key)), v); err != nil {
+				return err
+			}
+		}
+	case '[':
+		for i := 0; dec.More(); i++ {
+			if err := walkJSONValue(dec, data, append(path[:len(path):len(path)], spec.Index(i)), v); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Consume the closing delimiter.
+	_, err = dec.Token()
+	return err
+}
+
+// checkDuplicateKeys reports the first object in the JSON data that
+// repeats a key. The data must already be known to be valid JSON.
+func checkDuplicateKeys(data []byte) error {
+	seen := make(map[string]map[string]bool)
+	return walkJSON(data, jsonVisitor{key: func(object spec.NormalizedPath, key string) error {
+		p := object.String()
+		if seen[p] == nil {
+			seen[p] = make(map[string]bool)
+		}
+		if seen[p][key] {
+			return fmt.Errorf("duplicate key %q in %s", key, object)
+		}
+		seen[p][key] = true
+		return nil
+	}})
+}
+
+// jsonLines maps the normalized path of every value in the JSON data to
+// the line it starts on. The data must already be known to be valid JSON.
+func jsonLines(data []byte) (map[string]int, error) {
+	lines := make(map[string]int)
+	err := walkJSON(data, jsonVisitor{value: func(path spec.NormalizedPath, start int) {
+		lines[path.String()] = 1 + bytes.Count(data[:start], []byte("\n"))
+	}})
+	return lines, err
+}
+
+// jsonKeyOrder maps the normalized path of every object in the JSON data
+// to its keys in document order. The data must already be known to be valid JSON.
+func jsonKeyOrder(data []byte) (map[string][]string, error) {
+	order := make(map[string][]string)
+	err := walkJSON(data, jsonVisitor{key: func(object spec.NormalizedPath, key string) error {
+		order[object.String()] = append(order[object.String()], key)
+		return nil
+	}})
+	return order, err
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/zinrai/json-subset/subset"
+)
+
+// runExtract prints the part of the superset that the subset describes,
+// which can be saved as a smaller fixture or used as a new subset
+func runExtract(subsetData interface{}, supersetFile string, in inputOptions, at string, opts subset.Options, stdout, stderr io.Writer) int {
+	supersetData, _, err := loadInputSource(supersetFile, in)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading %s: %v\n", supersetFile, err)
+		return loadExitCode(err)
+	}
+	if at != "" {
+		supersetData, _, err = subset.LocateNode(supersetData, at)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error selecting --at in %s: %v\n", supersetFile, err)
+			return exitError
+		}
+	}
+
+	out, err := json.MarshalIndent(subset.Extract(subsetData, supersetData, opts), "", "  ")
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintln(stdout, string(out))
+	return exitSuccess
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// isURL reports whether an input argument is an http or https URL
+func isURL(name string) bool {
+	return strings.HasPrefix(name, "http://") || strings.HasPrefix(name, "https://")
+}
+
+// fetchURL downloads a document, failing on any non-2xx status. A zero
+// timeout waits indefinitely. Gzip-compressed bodies are decompressed.
+// Transient failures, meaning connection errors and 5xx statuses, are
+// retried up to retries times, retryDelay apart; other failures are not.
+func fetchURL(rawURL string, timeout time.Duration, retries int, retryDelay time.Duration) ([]byte, error) {
+	client := &http.Client{Timeout: timeout}
+	for attempt := 0; ; attempt++ {
+		data, transient, err := fetchOnce(client, rawURL)
+		if err == nil || !transient {
+			return data, err
+		}
+		if attempt >= retries {
+			if retries > 0 {
+				err = fmt.Errorf("%w (gave up after %d retries)", err, retries)
+			}
+			return nil, err
+		}
+		time.Sleep(retryDelay)
+	}
+}
+
+// fetchOnce makes a single request, reporting whether a failure is worth
+// retrying
+func fetchOnce(client *http.Client, rawURL string) (data []byte, transient bool, err error) {
+	resp, err := client.Get(rawURL)
+	if err != nil {
+		return nil, true, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, resp.StatusCode >= 500, fmt.Errorf("%s: %s", rawURL, resp.Status)
+	}
+	r, err := decompress(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err = io.ReadAll(r)
+	// A connection dropped mid-body is as transient as one never made.
+	return data, err != nil, err
+}
+
+// urlFormatName returns the part of a URL whose extension hints at the
+// format, ignoring the query string and fragment
+func urlFormatName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return path.Base(u.Path)
+}
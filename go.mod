@@ -2,4 +2,10 @@ module github.com/zinrai/json-subset
 
 go 1.24.0
 
-require github.com/theory/jsonpath v0.12.0
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/theory/jsonpath v0.12.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require github.com/kr/text v0.2.0 // indirect
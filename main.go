@@ -1,16 +1,29 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/theory/jsonpath/spec"
+	"github.com/zinrai/json-subset/subset"
 )
 
 const (
-	exitSuccess = 0
-	exitFailure = 1
-	exitError   = 2
+	exitSuccess    = 0
+	exitFailure    = 1
+	exitError      = 2
+	exitParseError = 3
+	exitEmpty      = 4
 )
 
 func main() {
@@ -18,62 +31,994 @@ func main() {
 }
 
 func run(args []string, stdout, stderr io.Writer) int {
-	if len(args) != 2 {
-		fmt.Fprintf(stderr, "Usage: json-subset <subset.json> <superset.json>\n")
-		fmt.Fprintf(stderr, "\nCheck if the first JSON is a subset of the second JSON.\n")
-		fmt.Fprintf(stderr, "Arrays are compared as sets (order is ignored).\n")
+	fs := flag.NewFlagSet("json-subset", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { usage(fs, stderr) }
+
+	arrayOrder := fs.String("array-order", "set", "array comparison mode: set, ordered or multiset")
+	sortArrays := fs.Bool("sort-arrays", false, "sort arrays of primitives on both sides before comparing, for use with -array-order=ordered")
+	arrayKey := fs.String("array-key", "", "pair object elements of arrays by this key (e.g. id) and compare each pair")
+	arrayAsObject := fs.Bool("array-as-object", false, "let an array match an object with keys \"0\", \"1\", ..., pairing element i with key \"i\"")
+	arrayExactLength := fs.Bool("array-exact-length", false, "require arrays to have the same length")
+	epsilon := fs.Float64("epsilon", 0, "maximum absolute difference for numbers to be considered equal")
+	normalizeNumbers := fs.Bool("normalize-numbers", false, "compare numbers by exact decimal value, so 1.10 equals 1.1 but no float rounding is tolerated")
+	allowTypeWidening := fs.Bool("allow-superset-type-widening", false, "let a scalar subset value match a superset array holding only that value, e.g. 1 and [1]")
+	strictTypes := fs.Bool("strict-types", false, "only match primitives of the same type and spelling, overriding --epsilon, --normalize-numbers, --empty-equals-null and --coerce-bool")
+	ignoreCase := fs.Bool("ignore-case", false, "compare string values case-insensitively")
+	coerceBool := fs.Bool("coerce-bool", false, "let the strings \"true\" and \"false\" equal the booleans")
+	emptyEqualsNull := fs.Bool("empty-equals-null", false, "let the empty string \"\" and null match each other")
+	trimStrings := fs.Bool("trim-strings", false, "ignore leading and trailing whitespace in string values")
+	ignoreKeyCase := fs.Bool("ignore-key-case", false, "match object keys case-insensitively")
+	ignoreKeyWhitespace := fs.Bool("ignore-whitespace-in-keys", false, "match object keys ignoring leading and trailing whitespace")
+	output := fs.String("output", "text", "output format: text, json, jsonpatch, merged, unified, github, table or tap")
+	format := fs.String("format", "auto", "input format: auto, json, jsonc, yaml, toml or xml")
+	xmlAttrPrefix := fs.String("xml-attr-prefix", defaultXMLMapping.attrPrefix, "with XML input, prefix attribute names with this to make their keys")
+	xmlTextKey := fs.String("xml-text-key", defaultXMLMapping.textKey, "with XML input, key holding the text of an element that has attributes or children")
+	preserveKeyOrder := fs.Bool("preserve-key-order", false, "show object keys in the order of the subset file instead of sorted (JSON subsets only)")
+	lineNumbers := fs.Bool("line-numbers", false, "show the superset line each difference refers to (JSON supersets only)")
+	rejectDuplicateKeys := fs.Bool("reject-duplicate-keys", false, "fail to load JSON objects that repeat a key")
+	ignoreValues := fs.Bool("ignore-values", false, "compare only structure and types, not scalar values")
+	showExtra := fs.Bool("show-extra", false, "also show superset keys that are not in the subset, prefixed with +")
+	enableWildcard := fs.Bool("enable-wildcard", false, "treat a subset key \"*\" as matching any key in the superset object")
+	nullMeansOptional := fs.Bool("null-means-optional", false, "let a null subset value also match a missing superset key")
+	ignoreNullValues := fs.Bool("ignore-null-values", false, "skip subset keys whose value is null, checking neither presence nor value")
+	intersection := fs.Bool("intersection", false, "only compare keys present in both documents")
+	parallel := fs.Int("parallel", 1, "compare top-level object branches in up to N goroutines (0 = one per CPU)")
+	firstOnly := fs.Int("first-only", 0, "only compare the first N keys (sorted) of each subset object, for debugging")
+	failFast := fs.Bool("fail-fast", false, "stop at the first difference")
+	disallowEmpty := fs.Bool("disallow-empty", false, "exit with code 4 if the subset is {}, [] or null")
+	matchMode := fs.String("match-mode", "any", "with several supersets, require the subset in any or all of them")
+	swap := fs.Bool("swap", false, "take the superset first and the subset second")
+	not := fs.Bool("not", false, "succeed only if the first JSON is NOT a subset of the second")
+	enableMatchers := fs.Bool("enable-matchers", false, "treat subset strings like \"contains:text\" as matchers")
+	enableRegex := fs.Bool("enable-regex", false, "treat subset strings like \"re:/pattern/\" as regular expressions")
+	quiet := fs.Bool("quiet", false, "print nothing; report the result only through the exit code")
+	fs.BoolVar(quiet, "q", false, "shorthand for --quiet")
+	valueWidth := fs.Int("value-width", subset.DefaultValueWidth, "truncate values shown in diffs to N characters (0 = unlimited)")
+	context := fs.Int("context", -1, "show only N lines around each difference, collapsing the rest into \"@@ ... @@\" (-1 = whole subset)")
+	diffOnlyValues := fs.Bool("diff-only-values", false, "print one \"path: subset != superset\" line per difference instead of the subset tree")
+	pathStyle := fs.String("path-style", "jsonpath", "how paths are written in diff output: jsonpath, pointer or dotted")
+	maxDiffs := fs.Int("max-diffs", 0, "show at most N differences in the diff output (0 = unlimited)")
+	diffMarker := fs.String("diff-marker", "-", "single character prefixing lines with a difference")
+	okMarker := fs.String("ok-marker", " ", "single character prefixing unchanged lines")
+	color := fs.String("color", "auto", "color diff lines: auto, always or never")
+	maxDepth := fs.Int("max-depth", -1, "do not compare values nested deeper than N (0 checks only top-level key presence)")
+	at := fs.String("at", "", "compare against the superset node selected by this JSONPath (e.g. $.data.user)")
+	timeout := fs.Duration("timeout", 30*time.Second, "time limit for fetching an http(s) URL argument (0 = none)")
+	retry := fs.Int("retry", 0, "retry fetching an http(s) URL argument up to N times after a connection error or 5xx status")
+	retryDelay := fs.Duration("retry-delay", time.Second, "wait this long between retries of --retry")
+	batch := fs.String("batch", "", "compare every subset/superset pair listed in a JSON or CSV manifest")
+	schemaFile := fs.String("schema", "", "validate each file argument against this JSON Schema (type, required and enum keywords only)")
+	combined := fs.Bool("combined", false, "read the subset and superset from the \"subset\" and \"superset\" keys of one file")
+	subsets := fs.Bool("subsets", false, "treat every file argument but the last as a subset of the last; all must be contained")
+	selfCheck := fs.Bool("self-check", false, "check that each file argument is a subset of itself, which must always pass")
+	ndjson := fs.Bool("ndjson", false, "compare newline-delimited JSON line by line")
+	extract := fs.Bool("extract", false, "print the part of the superset the subset describes, as JSON, instead of comparing")
+	explain := fs.Bool("explain", false, "show the whole subset with matched leaves marked \"# ok\"")
+	showStats := fs.Bool("stats", false, "print the number of keys, elements and values compared to stderr")
+	progress := fs.Bool("progress", false, "print the number of values compared so far to stderr every 250ms during long comparisons")
+	rulesFile := fs.String("rules", "", "JSON file mapping paths to comparison directives, e.g. {\"$.price\": \"epsilon:0.01\"}")
+	requiredKeys := fs.String("required-keys", "", "JSON file with an array of keys every superset object must have")
+	var ignore stringList
+	fs.Var(&ignore, "ignore", "skip a key glob (e.g. timestamp) or JSONPath (e.g. $.meta.requestId); repeatable")
+	var only stringList
+	fs.Var(&only, "only", "compare only subset locations matching a JSONPath (e.g. $.user.id) or key glob, skipping the rest; repeatable")
+	var parseEmbedded stringList
+	fs.Var(&parseEmbedded, "parse-embedded", "compare string values at a key glob or JSONPath as the JSON they contain; repeatable")
+	var timeFields stringList
+	fs.Var(&timeFields, "time-fields", "compare RFC 3339 string values at a key glob or JSONPath by instant; repeatable")
+	var keyMap stringList
+	fs.Var(&keyMap, "map", "look up a subset key under another name in the superset, e.g. userName=username; repeatable")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if fs.NArg() < 2 && *batch == "" && !((*selfCheck || *combined || *schemaFile != "") && fs.NArg() > 0) {
+		fs.Usage()
+		return exitError
+	}
+	// Stdin can only be read once.
+	if err := checkStdinOnce(append(fs.Args(), *rulesFile, *requiredKeys, *batch, *schemaFile)...); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	opts := subset.Options{
+		Epsilon:             *epsilon,
+		NormalizeNumbers:    *normalizeNumbers,
+		StrictTypes:         *strictTypes,
+		AllowTypeWidening:   *allowTypeWidening,
+		IgnoreCase:          *ignoreCase,
+		TrimStrings:         *trimStrings,
+		CoerceBool:          *coerceBool,
+		EmptyEqualsNull:     *emptyEqualsNull,
+		IgnoreKeyCase:       *ignoreKeyCase,
+		IgnoreKeyWhitespace: *ignoreKeyWhitespace,
+		EnableRegex:         *enableRegex,
+		LimitDepth:          *maxDepth >= 0,
+		MaxDepth:            *maxDepth,
+		ArrayKey:            *arrayKey,
+		ArrayAsObject:       *arrayAsObject,
+		ArrayExactLength:    *arrayExactLength,
+		EnableWildcard:      *enableWildcard,
+		IgnoreValues:        *ignoreValues,
+		ShowExtra:           *showExtra,
+		NullMeansOptional:   *nullMeansOptional,
+		IgnoreNullValues:    *ignoreNullValues,
+		FailFast:            *failFast,
+		Intersection:        *intersection,
+		EnableMatchers:      *enableMatchers,
+		Parallel:            *parallel,
+		FirstKeys:           *firstOnly,
+	}
+	if opts.Parallel <= 0 {
+		opts.Parallel = runtime.GOMAXPROCS(0)
+	}
+	var err error
+	opts.ArrayOrder, err = subset.ParseArrayOrder(*arrayOrder)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	for _, pattern := range ignore {
+		p, err := subset.ParsePathPattern(pattern)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: invalid ignore pattern %q: %v\n", pattern, err)
+			return exitError
+		}
+		opts.Ignore = append(opts.Ignore, p)
+	}
+
+	for _, pattern := range only {
+		p, err := subset.ParsePathPattern(pattern)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: invalid only pattern %q: %v\n", pattern, err)
+			return exitError
+		}
+		opts.Only = append(opts.Only, p)
+	}
+
+	for _, pattern := range parseEmbedded {
+		p, err := subset.ParsePathPattern(pattern)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: invalid parse-embedded pattern %q: %v\n", pattern, err)
+			return exitError
+		}
+		opts.ParseEmbedded = append(opts.ParseEmbedded, p)
+	}
+
+	for _, pattern := range timeFields {
+		p, err := subset.ParsePathPattern(pattern)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: invalid time-fields pattern %q: %v\n", pattern, err)
+			return exitError
+		}
+		opts.TimeFields = append(opts.TimeFields, p)
+	}
+
+	for _, mapping := range keyMap {
+		m, err := subset.ParseKeyMapping(mapping)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+		opts.KeyMap = append(opts.KeyMap, m)
+	}
+
+	if *showStats && !*quiet {
+		stats := &subset.Stats{}
+		opts.Stats = stats
+		defer fmt.Fprintf(stderr, "Stats: %s\n", stats)
+	}
+	if *progress && !*quiet {
+		opts.Progress = func(values int) {
+			fmt.Fprintf(stderr, "Progress: %d values compared\n", values)
+		}
+	}
+
+	if *rulesFile != "" {
+		opts.Rules, err = loadRules(*rulesFile)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading %s: %v\n", *rulesFile, err)
+			return loadExitCode(err)
+		}
+	}
+
+	if *requiredKeys != "" {
+		opts.RequiredKeys, err = loadRequiredKeys(*requiredKeys)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading %s: %v\n", *requiredKeys, err)
+			return loadExitCode(err)
+		}
+	}
+
+	switch *output {
+	case "text", "json", "jsonpatch", "merged", "unified", "github", "table", "tap":
+	default:
+		fmt.Fprintf(stderr, "Error: invalid output format %q (want text, json, jsonpatch, merged, unified, github, table or tap)\n", *output)
 		return exitError
 	}
 
-	subsetFile := args[0]
-	supersetFile := args[1]
+	switch *format {
+	case "auto", "json", "jsonc", "yaml", "toml", "xml":
+	default:
+		fmt.Fprintf(stderr, "Error: invalid input format %q (want auto, json, jsonc, yaml, toml or xml)\n", *format)
+		return exitError
+	}
 
-	subsetData, err := loadJSON(subsetFile)
+	if *normalizeNumbers && *epsilon != 0 {
+		fmt.Fprintln(stderr, "Error: --normalize-numbers and --epsilon cannot be combined")
+		return exitError
+	}
+
+	if utf8.RuneCountInString(*diffMarker) != 1 || utf8.RuneCountInString(*okMarker) != 1 {
+		fmt.Fprintln(stderr, "Error: --diff-marker and --ok-marker must be a single character")
+		return exitError
+	}
+
+	formatOpts := subset.FormatOptions{
+		ValueWidth:   *valueWidth,
+		DiffMarker:   *diffMarker,
+		OKMarker:     *okMarker,
+		MaxDiffs:     *maxDiffs,
+		LimitContext: *context >= 0,
+		Context:      *context,
+		ValuesOnly:   *diffOnlyValues,
+	}
+	formatOpts.PathStyle, err = subset.ParsePathStyle(*pathStyle)
 	if err != nil {
-		fmt.Fprintf(stderr, "Error loading %s: %v\n", subsetFile, err)
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	switch *color {
+	case "auto":
+		formatOpts.Color = isTerminal(stderr)
+	case "always":
+		formatOpts.Color = true
+	case "never":
+	default:
+		fmt.Fprintf(stderr, "Error: invalid color mode %q (want auto, always or never)\n", *color)
+		return exitError
+	}
+
+	if *matchMode != "any" && *matchMode != "all" {
+		fmt.Fprintf(stderr, "Error: invalid match mode %q (want any or all)\n", *matchMode)
+		return exitError
+	}
+
+	in := inputOptions{
+		format:              *format,
+		xml:                 &xmlMapping{attrPrefix: *xmlAttrPrefix, textKey: *xmlTextKey},
+		rejectDuplicateKeys: *rejectDuplicateKeys,
+		timeout:             *timeout,
+		retries:             *retry,
+		retryDelay:          *retryDelay,
+	}
+
+	if *sortArrays && (*batch != "" || *ndjson || *subsets || *selfCheck || *schemaFile != "" || *extract || *lineNumbers) {
+		fmt.Fprintln(stderr, "Error: --sort-arrays does not support --batch, --ndjson, --subsets, --self-check, --schema, --extract or --line-numbers")
+		return exitError
+	}
+	if *extract && (*batch != "" || *ndjson || *output != "text" || *not || *matchMode != "any") {
+		fmt.Fprintln(stderr, "Error: --extract does not support --batch, --ndjson, --output, --not or --match-mode")
 		return exitError
 	}
 
-	supersetData, err := loadJSON(supersetFile)
+	if *combined && (fs.NArg() != 1 || *batch != "" || *ndjson || *subsets || *selfCheck || *swap || *lineNumbers || *preserveKeyOrder) {
+		fmt.Fprintln(stderr, "Error: --combined takes exactly one file and does not support --batch, --ndjson, --subsets, --self-check, --swap, --line-numbers or --preserve-key-order")
+		return exitError
+	}
+
+	if *batch != "" {
+		if fs.NArg() > 0 || *ndjson || *output != "text" || *not || *disallowEmpty || *swap || *selfCheck || *schemaFile != "" || *subsets || *matchMode != "any" {
+			fmt.Fprintln(stderr, "Error: --batch takes no file arguments and does not support --ndjson, --output, --not, --disallow-empty, --swap, --self-check, --schema, --subsets or --match-mode")
+			return exitError
+		}
+		return runBatch(*batch, in, *at, opts, formatOpts, *quiet, stdout, stderr)
+	}
+
+	if *schemaFile != "" {
+		if *ndjson || *output != "text" || *not || *swap || *extract || *subsets || *selfCheck || *combined || *matchMode != "any" {
+			fmt.Fprintln(stderr, "Error: --schema does not support --ndjson, --output, --not, --swap, --extract, --subsets, --self-check, --combined or --match-mode")
+			return exitError
+		}
+		files, err := expandGlobs(fs.Args())
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+		return runSchema(files, *schemaFile, in, formatOpts, *quiet, stdout, stderr)
+	}
+
+	if *selfCheck {
+		if *ndjson || *output != "text" || *not || *swap || *extract || *subsets || *matchMode != "any" {
+			fmt.Fprintln(stderr, "Error: --self-check does not support --ndjson, --output, --not, --swap, --extract, --subsets or --match-mode")
+			return exitError
+		}
+		files, err := expandGlobs(fs.Args())
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+		return runSelfCheck(files, in, opts, formatOpts, *quiet, stdout, stderr)
+	}
+
+	if *subsets {
+		if *ndjson || *output != "text" || *not || *swap || *extract || *matchMode != "any" {
+			fmt.Fprintln(stderr, "Error: --subsets does not support --ndjson, --output, --not, --swap, --extract or --match-mode")
+			return exitError
+		}
+		files, err := expandGlobs(fs.Args()[:fs.NArg()-1])
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+		return runSubsets(files, fs.Arg(fs.NArg()-1), in, *at, opts, formatOpts, *quiet, stdout, stderr)
+	}
+
+	subsetFile := fs.Arg(0)
+	supersetFiles := fs.Args()[1:]
+	if *swap {
+		if fs.NArg() != 2 {
+			fmt.Fprintln(stderr, "Error: --swap takes exactly two files, the superset and then the subset")
+			return exitError
+		}
+		subsetFile, supersetFiles = fs.Arg(1), []string{fs.Arg(0)}
+	}
+	supersetFiles, err = expandGlobs(supersetFiles)
 	if err != nil {
-		fmt.Fprintf(stderr, "Error loading %s: %v\n", supersetFile, err)
+		fmt.Fprintf(stderr, "Error: %v\n", err)
 		return exitError
 	}
+	if *combined {
+		var supersetFile string
+		subsetFile, supersetFile, in, err = loadCombined(subsetFile, in)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading %s: %v\n", fs.Arg(0), err)
+			return loadExitCode(err)
+		}
+		supersetFiles = []string{supersetFile}
+	}
+
+	if *ndjson {
+		if len(supersetFiles) > 1 || *output != "text" || *not || *at != "" || *rejectDuplicateKeys || *lineNumbers || *disallowEmpty {
+			fmt.Fprintln(stderr, "Error: --ndjson takes exactly one superset and does not support --output, --not, --at, --reject-duplicate-keys, --line-numbers or --disallow-empty")
+			return exitError
+		}
+		return runNDJSON(subsetFile, supersetFiles[0], opts, formatOpts, *quiet, stdout, stderr)
+	}
+
+	subsetIn, supersetIn := in, in
+	subsetIn.keyOrder = *preserveKeyOrder
+	supersetIn.lineNumbers = *lineNumbers
+
+	subsetData, subsetSource, err := loadInputSource(subsetFile, subsetIn)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading %s: %v\n", subsetFile, err)
+		return loadExitCode(err)
+	}
+	formatOpts.KeyOrder = subsetSource.keyOrder
+	if *disallowEmpty && isEmpty(subsetData) {
+		fmt.Fprintf(stderr, "Error: subset %s is empty\n", subsetFile)
+		return exitEmpty
+	}
+	warnings := stderr
+	if *quiet {
+		warnings = io.Discard
+	}
+	if *sortArrays {
+		subsetData = sortDocument(subsetData, subsetFile, warnings)
+	}
+
+	if *extract {
+		if len(supersetFiles) != 1 {
+			fmt.Fprintln(stderr, "Error: --extract takes exactly one superset")
+			return exitError
+		}
+		return runExtract(subsetData, supersetFiles[0], supersetIn, *at, opts, stdout, stderr)
+	}
+
+	// In any mode, the subset only has to be contained in one of the supersets.
+	var failures []failure
+	var matchedDiffs []subset.Diff
+	var matchedPaths []spec.NormalizedPath
+	var passed []string
+	for _, supersetFile := range supersetFiles {
+		supersetData, supersetSource, err := loadInputSource(supersetFile, supersetIn)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading %s: %v\n", supersetFile, err)
+			return loadExitCode(err)
+		}
+		var base spec.NormalizedPath
+		if *at != "" {
+			supersetData, base, err = subset.LocateNode(supersetData, *at)
+			if err != nil {
+				fmt.Fprintf(stderr, "Error selecting --at in %s: %v\n", supersetFile, err)
+				return exitError
+			}
+		}
+		if *sortArrays {
+			supersetData = sortDocument(supersetData, supersetFile, warnings)
+		}
 
-	isSubset, diffs := checkSubsetWithDiffs(subsetData, supersetData)
+		var isSubset bool
+		var diffs []subset.Diff
+		var matches []spec.NormalizedPath
+		if *explain {
+			isSubset, diffs, matches = subset.CheckSubsetExplain(subsetData, supersetData, opts)
+		} else {
+			isSubset, diffs = subset.CheckSubsetWithOptions(subsetData, supersetData, opts)
+		}
+		if supersetSource.lines != nil {
+			subset.AddSupersetLines(diffs, supersetSource.lines, base)
+		}
+		if isSubset {
+			if len(passed) == 0 {
+				matchedDiffs = diffs
+				matchedPaths = matches
+			}
+			passed = append(passed, supersetFile)
+			if *matchMode == "any" {
+				break
+			}
+			continue
+		}
+		failures = append(failures, failure{file: supersetFile, diffs: diffs, matches: matches})
+	}
+	isSubset := len(passed) > 0
+	matched := ""
+	if *matchMode == "all" {
+		isSubset = len(failures) == 0
+		matched = fmt.Sprintf("all %d files", len(supersetFiles))
+	} else if isSubset {
+		matched = passed[0]
+	}
+	multiple := len(supersetFiles) > 1
+
+	if *quiet {
+		if isSubset != *not {
+			return exitSuccess
+		}
+		return exitFailure
+	}
+
+	if *output == "unified" {
+		// Like diff -u, nothing is printed when there is nothing to change.
+		if !isSubset {
+			for _, f := range failures {
+				fmt.Fprint(stdout, subset.FormatDiffUnified(subsetData, f.diffs, f.file, subsetFile))
+			}
+		}
+		if isSubset != *not {
+			return exitSuccess
+		}
+		return exitFailure
+	}
+
+	if *output == "table" {
+		if isSubset {
+			// Only informational diffs such as extra keys can remain.
+			if len(matchedDiffs) > 0 {
+				fmt.Fprint(stdout, subset.FormatDiffTable(matchedDiffs, formatOpts))
+			}
+		} else {
+			for i, f := range failures {
+				if multiple {
+					if i > 0 {
+						fmt.Fprintln(stdout)
+					}
+					fmt.Fprintf(stdout, "--- %s\n", f.file)
+				}
+				fmt.Fprint(stdout, subset.FormatDiffTable(f.diffs, formatOpts))
+			}
+		}
+		if isSubset != *not {
+			return exitSuccess
+		}
+		return exitFailure
+	}
+
+	if *output == "github" {
+		if !isSubset {
+			for _, f := range failures {
+				fmt.Fprint(stdout, subset.FormatDiffGitHubWithOptions(f.diffs, annotationFile(f.file), formatOpts))
+			}
+		} else if *not {
+			fmt.Fprintf(stdout, "::error::First JSON is unexpectedly a subset of %s\n", matched)
+		}
+		if isSubset != *not {
+			return exitSuccess
+		}
+		return exitFailure
+	}
+
+	if *output == "tap" {
+		diffs := matchedDiffs
+		if !isSubset {
+			diffs = nil
+			for _, f := range failures {
+				diffs = append(diffs, f.diffs...)
+			}
+		}
+		fmt.Fprint(stdout, subset.FormatDiffTAPWithOptions(isSubset != *not, tapDescription(subsetFile, supersetFiles, *matchMode, *not), diffs, formatOpts))
+		if isSubset != *not {
+			return exitSuccess
+		}
+		return exitFailure
+	}
+
+	if formatter, ok := structuredFormatter(*output, subsetData, formatOpts); ok {
+		jsonOutput, err := formatFailures(failures, matchedDiffs, isSubset, multiple, formatter)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error formatting output: %v\n", err)
+			return exitError
+		}
+		fmt.Fprintln(stdout, jsonOutput)
+		if isSubset != *not {
+			return exitSuccess
+		}
+		return exitFailure
+	}
+
+	if *not {
+		if isSubset {
+			if multiple {
+				fmt.Fprintf(stderr, "FAIL: First JSON is unexpectedly a subset of %s.\n", matched)
+			} else {
+				fmt.Fprintln(stderr, "FAIL: First JSON is unexpectedly a subset of second JSON.")
+			}
+			return exitFailure
+		}
+		fmt.Fprintln(stdout, "OK: First JSON is not a subset of second JSON.")
+		return exitSuccess
+	}
 
 	if isSubset {
-		fmt.Fprintln(stdout, "OK: First JSON is a subset of second JSON.")
+		if multiple {
+			fmt.Fprintf(stdout, "OK: First JSON is a subset of %s.\n", matched)
+		} else {
+			fmt.Fprintln(stdout, "OK: First JSON is a subset of second JSON.")
+		}
+		// Only informational diffs such as extra keys can remain.
+		if len(matchedDiffs) > 0 || *explain {
+			formatOpts.Matched = matchedPaths
+			fmt.Fprintln(stdout, "")
+			fmt.Fprint(stdout, subset.FormatDiffOutputWithOptions(subsetData, matchedDiffs, formatOpts))
+		}
 		return exitSuccess
 	}
 
-	fmt.Fprintln(stderr, "FAIL: First JSON is not a subset of second JSON.")
-	fmt.Fprintln(stderr, "")
-	diffOutput := FormatDiffOutput(subsetData, diffs)
-	fmt.Fprint(stderr, diffOutput)
+	if !multiple {
+		fmt.Fprintln(stderr, "FAIL: First JSON is not a subset of second JSON.")
+		fmt.Fprintln(stderr, "")
+		formatOpts.Matched = failures[0].matches
+		diffOutput := subset.FormatDiffOutputWithOptions(subsetData, failures[0].diffs, formatOpts)
+		fmt.Fprint(stderr, diffOutput)
+		fmt.Fprintln(stderr, "")
+		fmt.Fprintln(stderr, subset.FormatDiffSummary(failures[0].diffs))
+		return exitFailure
+	}
+
+	if *matchMode == "all" {
+		fmt.Fprintf(stderr, "FAIL: First JSON is not a subset of %d of the %d files.\n", len(failures), len(supersetFiles))
+	} else {
+		fmt.Fprintf(stderr, "FAIL: First JSON is not a subset of any of the %d files.\n", len(supersetFiles))
+	}
+	for _, f := range failures {
+		fmt.Fprintf(stderr, "\n--- %s\n", f.file)
+		formatOpts.Matched = f.matches
+		fmt.Fprint(stderr, subset.FormatDiffOutputWithOptions(subsetData, f.diffs, formatOpts))
+		fmt.Fprintln(stderr, subset.FormatDiffSummary(f.diffs))
+	}
 	return exitFailure
 }
 
-func loadJSON(filename string) (interface{}, error) {
-	var data []byte
-	var err error
+// loadRequiredKeys reads a JSON array of key names
+func loadRequiredKeys(filename string) ([]string, error) {
+	data, err := loadJSON(filename, "auto")
+	if err != nil {
+		return nil, err
+	}
+	list, ok := data.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("required keys must be a JSON array of strings")
+	}
+	keys := make([]string, 0, len(list))
+	for _, v := range list {
+		key, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("required keys must be a JSON array of strings, got %v", v)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// loadRules reads a JSON object mapping path patterns to comparison directives
+func loadRules(filename string) ([]subset.Rule, error) {
+	data, err := loadJSON(filename, "auto")
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("rules must be a JSON object of strings")
+	}
+	directives := make(map[string]string, len(obj))
+	for pattern, v := range obj {
+		directive, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("rule %q: directive must be a string, got %v", pattern, v)
+		}
+		directives[pattern] = directive
+	}
+	return subset.ParseRules(directives)
+}
+
+// stringList is a flag.Value collecting repeated string flags
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// failure holds the differences against one superset file
+type failure struct {
+	file    string
+	diffs   []subset.Diff
+	matches []spec.NormalizedPath
+}
+
+// tapDescription names the single TAP test point after what is checked
+func tapDescription(subsetFile string, supersetFiles []string, matchMode string, not bool) string {
+	verb := "is a subset of"
+	if not {
+		verb = "is not a subset of"
+	}
+	target := supersetFiles[0]
+	if len(supersetFiles) > 1 {
+		target = matchMode + " of " + strings.Join(supersetFiles, ", ")
+	}
+	return fmt.Sprintf("%s %s %s", subsetFile, verb, target)
+}
+
+// structuredFormatter returns the formatter producing JSON for an --output
+// value, or false if the output is not JSON. JSON Patch paths are always
+// JSON Pointers, whatever the path style.
+func structuredFormatter(output string, subsetData interface{}, formatOpts subset.FormatOptions) (func([]subset.Diff) (string, error), bool) {
+	switch output {
+	case "json":
+		return func(diffs []subset.Diff) (string, error) {
+			return subset.FormatDiffJSONWithOptions(diffs, formatOpts)
+		}, true
+	case "jsonpatch":
+		return subset.FormatDiffJSONPatch, true
+	case "merged":
+		return func(diffs []subset.Diff) (string, error) {
+			return subset.FormatDiffMerged(subsetData, diffs)
+		}, true
+	default:
+		return nil, false
+	}
+}
+
+// formatFailures renders the diffs with a JSON formatter, or as an object
+// keyed by file name when several supersets were given.
+func formatFailures(failures []failure, matchedDiffs []subset.Diff, isSubset, multiple bool, format func([]subset.Diff) (string, error)) (string, error) {
+	if isSubset {
+		return format(matchedDiffs)
+	}
+	if !multiple {
+		return format(failures[0].diffs)
+	}
+
+	byFile := make(map[string]json.RawMessage, len(failures))
+	for _, f := range failures {
+		out, err := format(f.diffs)
+		if err != nil {
+			return "", err
+		}
+		byFile[f.file] = json.RawMessage(out)
+	}
+	data, err := json.MarshalIndent(byFile, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func usage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintf(w, "Usage: json-subset [options] <subset.json> <superset.json> [<superset.json>...]\n")
+	fmt.Fprintf(w, "       json-subset [options] --batch <manifest>\n")
+	fmt.Fprintf(w, "       json-subset [options] --subsets <subset.json>... <superset.json>\n")
+	fmt.Fprintf(w, "       json-subset [options] --self-check <file.json>...\n")
+	fmt.Fprintf(w, "       json-subset [options] --combined <case.json>\n")
+	fmt.Fprintf(w, "       json-subset [options] --schema <schema.json> <file.json>...\n")
+	fmt.Fprintf(w, "\nCheck if the first JSON is a subset of the second JSON.\n")
+	fmt.Fprintf(w, "A superset may also be an http(s) URL, which is fetched.\n")
+	fmt.Fprintf(w, "With several supersets, the check succeeds if any of them contains the first JSON\n")
+	fmt.Fprintf(w, "(or all of them, with -match-mode=all). Quoted globs such as 'dumps/*.json' are expanded.\n")
+	fmt.Fprintf(w, "Arrays are compared as sets (order is ignored) unless -array-order=ordered is given.\n")
+	fmt.Fprintf(w, "\nOptions:\n")
+	fs.PrintDefaults()
+}
+
+// isTerminal reports whether w is a character device such as a TTY
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// parseError reports input that was read but could not be decoded
+type parseError struct {
+	err error
+}
+
+func (e *parseError) Error() string {
+	return e.err.Error()
+}
+
+func (e *parseError) Unwrap() error {
+	return e.err
+}
 
+// loadExitCode maps a load error to an exit code, separating invalid
+// documents from files that could not be read.
+func loadExitCode(err error) int {
+	var pe *parseError
+	if errors.As(err, &pe) {
+		return exitParseError
+	}
+	return exitError
+}
+
+// openInput opens a file, or stdin when filename is "-". Gzip-compressed
+// input is decompressed transparently.
+func openInput(filename string) (io.ReadCloser, error) {
 	if filename == "-" {
-		data, err = io.ReadAll(os.Stdin)
+		return decompress(io.NopCloser(os.Stdin))
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	return decompress(f)
+}
+
+// expandGlobs replaces each file argument containing glob characters with
+// the files it matches. A pattern matching nothing is an error unless a
+// file has that literal name.
+func expandGlobs(args []string) ([]string, error) {
+	var files []string
+	for _, arg := range args {
+		if arg == "-" || strings.HasPrefix(arg, literalPrefix) || isURL(arg) || !strings.ContainsAny(arg, "*?[") {
+			files = append(files, arg)
+			continue
+		}
+		matches, err := filepath.Glob(arg)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("invalid pattern %q: %w", arg, err)
+		}
+		if len(matches) == 0 {
+			if _, err := os.Stat(arg); err != nil {
+				return nil, fmt.Errorf("no files match %q", arg)
+			}
+			matches = []string{arg}
+		}
+		files = append(files, matches...)
+	}
+	return files, nil
+}
+
+// checkStdinOnce fails if more than one of names is "-", since only one
+// input can be read from stdin
+func checkStdinOnce(names ...string) error {
+	count := 0
+	for _, name := range names {
+		if name == "-" {
+			count++
+		}
+	}
+	if count > 1 {
+		return fmt.Errorf("only one input can be read from stdin (\"-\"), got %d", count)
+	}
+	return nil
+}
+
+// annotationFile returns the file name to attach to a GitHub annotation,
+// or "" for stdin, inline JSON and URLs, which are not files in the repository
+func annotationFile(name string) string {
+	if name == "-" || strings.HasPrefix(name, literalPrefix) || isURL(name) {
+		return ""
+	}
+	return name
+}
+
+// sortDocument sorts the arrays of primitives in doc for --sort-arrays,
+// warning about the arrays that keep their order
+func sortDocument(doc interface{}, name string, warnings io.Writer) interface{} {
+	sorted, unsorted := subset.SortArrays(doc)
+	for _, p := range unsorted {
+		fmt.Fprintf(warnings, "Warning: %s: array at %s is not sorted, its elements are not all primitives\n", name, p)
+	}
+	return sorted
+}
+
+// isEmpty reports whether a document is null, {} or [], which is a
+// subset of anything
+func isEmpty(doc interface{}) bool {
+	switch v := doc.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// literalPrefix marks an argument as inline JSON rather than a file name
+const literalPrefix = "json:"
+
+// inputOptions controls how input documents are decoded
+type inputOptions struct {
+	// format is auto, json, jsonc, yaml, toml or xml
+	format string
+	// xml maps XML to JSON values; nil means defaultXMLMapping
+	xml *xmlMapping
+	// rejectDuplicateKeys fails JSON objects that repeat a key, which
+	// json.Unmarshal would otherwise resolve by keeping the last value
+	rejectDuplicateKeys bool
+	// lineNumbers records where each JSON value starts in the source
+	lineNumbers bool
+	// keyOrder records the order of JSON object keys in the source
+	keyOrder bool
+	// timeout limits how long fetching an http(s) URL may take
+	timeout time.Duration
+	// retries is how often a transient fetch failure is retried,
+	// retryDelay apart
+	retries    int
+	retryDelay time.Duration
+	// documents holds already decoded documents by name, as split from
+	// a --combined file
+	documents map[string]interface{}
+}
+
+func loadJSON(filename, format string) (interface{}, error) {
+	return loadInput(filename, inputOptions{format: format})
+}
+
+func loadInput(filename string, in inputOptions) (interface{}, error) {
+	value, _, err := loadInputSource(filename, in)
+	return value, err
+}
+
+// source holds what inputOptions asked to record about a JSON document
+type source struct {
+	// lines maps normalized paths to the line the value starts on
+	lines map[string]int
+	// keyOrder maps object paths to their keys in document order
+	keyOrder map[string][]string
+}
+
+// loadInputSource is like loadInput but, for JSON input, also returns the
+// source information requested by in.lineNumbers and in.keyOrder
+func loadInputSource(filename string, in inputOptions) (interface{}, source, error) {
+	if doc, ok := in.documents[filename]; ok {
+		return doc, source{}, nil
+	}
+	data := []byte(strings.TrimPrefix(filename, literalPrefix))
+	format := "json"
+	if !strings.HasPrefix(filename, literalPrefix) {
+		var err error
+		name := filename
+		if isURL(filename) {
+			data, err = fetchURL(filename, in.timeout, in.retries, in.retryDelay)
+			name = urlFormatName(filename)
+		} else {
+			data, err = readInput(filename)
 		}
-	} else {
-		data, err = os.ReadFile(filename)
 		if err != nil {
-			return nil, err
+			return nil, source{}, err
+		}
+		format = in.format
+		if format == "auto" {
+			format = detectFormat(name)
 		}
 	}
 
-	var result interface{}
-	if err := json.Unmarshal(data, &result); err != nil {
+	switch format {
+	case "yaml":
+		value, err := decodeYAML(data)
+		return value, source{}, err
+	case "toml":
+		value, err := decodeTOML(data)
+		return value, source{}, err
+	case "xml":
+		m := defaultXMLMapping
+		if in.xml != nil {
+			m = *in.xml
+		}
+		value, err := decodeXML(data, m)
+		return value, source{}, err
+	case "jsonc":
+		var err error
+		if data, err = stripJSONC(data); err != nil {
+			return nil, source{}, err
+		}
+	}
+
+	value, err := decodeInputJSON(data, in)
+	if err != nil {
+		return nil, source{}, err
+	}
+	var src source
+	if in.lineNumbers {
+		if src.lines, err = jsonLines(data); err != nil {
+			return nil, source{}, &parseError{err}
+		}
+	}
+	if in.keyOrder {
+		if src.keyOrder, err = jsonKeyOrder(data); err != nil {
+			return nil, source{}, &parseError{err}
+		}
+	}
+	return value, src, nil
+}
+
+// readInput reads a whole file, or stdin when filename is "-"
+func readInput(filename string) ([]byte, error) {
+	r, err := openInput(filename)
+	if err != nil {
 		return nil, err
 	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// decodeInputJSON decodes JSON with the checks requested in in
+func decodeInputJSON(data []byte, in inputOptions) (interface{}, error) {
+	result, err := decodeJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	if in.rejectDuplicateKeys {
+		if err := checkDuplicateKeys(data); err != nil {
+			return nil, &parseError{err}
+		}
+	}
+	return result, nil
+}
+
+// decodeJSON decodes a JSON document, keeping numbers as json.Number so
+// integers beyond float64 precision compare exactly
+func decodeJSON(data []byte) (interface{}, error) {
+	var result interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, &parseError{err}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, &parseError{fmt.Errorf("invalid data after top-level value at offset %d", dec.InputOffset())}
+	}
 
 	return result, nil
 }
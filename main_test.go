@@ -0,0 +1,1350 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/zinrai/json-subset/subset"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	return writeFileIn(t, t.TempDir(), name, content)
+}
+
+func writeFileIn(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadYAMLAgainstJSON(t *testing.T) {
+	subsetFile := writeFile(t, "subset.yaml", "user:\n  name: alice\n  age: 30\ntags:\n  - admin\n")
+	supersetFile := writeFile(t, "superset.json", `{"user": {"name": "alice", "age": 30, "email": "a@example.com"}, "tags": ["dev", "admin"]}`)
+
+	subsetData, err := loadJSON(subsetFile, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON(%s) error = %v", subsetFile, err)
+	}
+	supersetData, err := loadJSON(supersetFile, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON(%s) error = %v", supersetFile, err)
+	}
+
+	if ok, diffs := subset.CheckSubset(subsetData, supersetData); !ok {
+		t.Errorf("YAML subset should be contained in JSON superset, diffs: %+v", diffs)
+	}
+}
+
+func TestLoadTOMLAgainstJSON(t *testing.T) {
+	subsetFile := writeFile(t, "subset.toml", `
+name = "app"
+port = 8080
+released = 2024-05-01T10:00:00Z
+day = 2024-05-01
+
+[[servers]]
+host = "b.example.com"
+`)
+	supersetFile := writeFile(t, "superset.json", `{
+		"name": "app", "port": 8080, "debug": false,
+		"released": "2024-05-01T10:00:00Z", "day": "2024-05-01",
+		"servers": [{"host": "a.example.com"}, {"host": "b.example.com", "weight": 2}]
+	}`)
+
+	subsetData, err := loadJSON(subsetFile, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON(%s) error = %v", subsetFile, err)
+	}
+	supersetData, err := loadJSON(supersetFile, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON(%s) error = %v", supersetFile, err)
+	}
+
+	if ok, diffs := subset.CheckSubset(subsetData, supersetData); !ok {
+		t.Errorf("TOML subset should be contained in JSON superset, diffs: %+v", diffs)
+	}
+
+	if _, err := loadJSON(writeFile(t, "bad.toml", "name = "), "auto"); loadExitCode(err) != exitParseError {
+		t.Errorf("invalid TOML should be a parse error, got %v", err)
+	}
+}
+
+func TestLoadLargeIntegers(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		superset string
+	}{
+		{"YAML", "subset.yaml", "id: 12345678901234567891\n", `{"id": 12345678901234567890}`},
+		{"YAML negative", "subset.yaml", "id: -9007199254740993\n", `{"id": -9007199254740992}`},
+		{"TOML", "subset.toml", "id = 9007199254740993\n", `{"id": 9007199254740992}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subsetData, err := loadJSON(writeFile(t, tt.file, tt.content), "auto")
+			if err != nil {
+				t.Fatal(err)
+			}
+			supersetData, err := loadJSON(writeFile(t, "superset.json", tt.superset), "auto")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok, _ := subset.CheckSubset(subsetData, supersetData); ok {
+				t.Errorf("%s integer beyond 2^53 should not equal its float64 neighbour", tt.name)
+			}
+			if ok, diffs := subset.CheckSubset(subsetData, subsetData); !ok {
+				t.Errorf("document should equal itself, diffs: %+v", diffs)
+			}
+		})
+	}
+}
+
+func TestStrictTypesAcrossFormats(t *testing.T) {
+	subsetFile := writeFile(t, "subset.yaml", "age: 30\nprice: 9.99\n")
+	tests := []struct {
+		name     string
+		superset string
+		want     bool
+	}{
+		{"same spelling", `{"age": 30, "price": 9.99, "name": "alice"}`, true},
+		{"different spelling", `{"age": 30.0, "price": 9.99}`, false},
+		{"different value", `{"age": 31, "price": 9.99}`, false},
+	}
+
+	subsetData, err := loadJSON(subsetFile, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON(%s) error = %v", subsetFile, err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			supersetData, err := loadJSON(writeFile(t, "superset.json", tt.superset), "auto")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, diffs := subset.CheckSubsetWithOptions(subsetData, supersetData, subset.Options{StrictTypes: true})
+			if got != tt.want {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v, diffs: %+v", got, tt.want, diffs)
+			}
+		})
+	}
+}
+
+func TestLoadXMLAgainstJSON(t *testing.T) {
+	supersetFile := writeFile(t, "superset.xml", `<?xml version="1.0"?>
+<!-- fixture -->
+<user id="42" xmlns:x="urn:x">
+  <name>Alice</name>
+  <x:email>a@example.com</x:email>
+  <role>admin</role>
+  <role>dev</role>
+  <note lang="en">hello</note>
+  <empty/>
+</user>
+`)
+	subsetFile := writeFile(t, "subset.json", `{"user": {"@id": "42", "email": "a@example.com", "role": ["dev"], "note": {"#text": "hello"}, "empty": ""}}`)
+
+	subsetData, err := loadJSON(subsetFile, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON(%s) error = %v", subsetFile, err)
+	}
+	supersetData, err := loadJSON(supersetFile, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON(%s) error = %v", supersetFile, err)
+	}
+
+	if ok, diffs := subset.CheckSubset(subsetData, supersetData); !ok {
+		t.Errorf("JSON subset should be contained in XML superset, diffs: %+v", diffs)
+	}
+
+	for _, bad := range []string{"<a><b></a>", "<a/><b/>", "", "text<a/>"} {
+		if _, err := loadJSON(writeFile(t, "bad.xml", bad), "auto"); loadExitCode(err) != exitParseError {
+			t.Errorf("loadJSON(%q) should be a parse error, got %v", bad, err)
+		}
+	}
+}
+
+func TestDecodeXMLMapping(t *testing.T) {
+	got, err := decodeXML([]byte(`<a id="1">x<b>y</b></a>`), xmlMapping{attrPrefix: "attr_", textKey: "_text"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"a": map[string]interface{}{"attr_id": "1", "b": "y", "_text": "x"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("decodeXML() = %v, want %v", got, want)
+	}
+}
+
+func TestLoadJSONCAgainstJSON(t *testing.T) {
+	subsetFile := writeFile(t, "subset.jsonc", `{
+  // the service must report its homepage
+  "url": "http://example.com/a//b", /* not a comment: "/*" */
+  "tags": [
+    "admin", // trailing comma below
+  ],
+}
+`)
+	supersetFile := writeFile(t, "superset.json", `{"url": "http://example.com/a//b", "tags": ["dev", "admin"], "id": 1}`)
+
+	subsetData, err := loadJSON(subsetFile, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON(%s) error = %v", subsetFile, err)
+	}
+	supersetData, err := loadJSON(supersetFile, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON(%s) error = %v", supersetFile, err)
+	}
+
+	if ok, diffs := subset.CheckSubset(subsetData, supersetData); !ok {
+		t.Errorf("JSONC subset should be contained in JSON superset, diffs: %+v", diffs)
+	}
+}
+
+func TestLoadRejectsNonFiniteNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		want    string
+	}{
+		{"YAML infinity", "doc.yaml", "limits:\n  max: .inf\n", "non-finite number +Inf at $['limits']['max']"},
+		{"YAML NaN", "doc.yaml", "- 1\n- .nan\n", "non-finite number NaN at $[1]"},
+		{"TOML negative infinity", "doc.toml", "min = -inf\n", "non-finite number -Inf at $['min']"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadJSON(writeFile(t, tt.file, tt.content), "auto")
+			var perr *parseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("loadJSON() error = %v, want a parse error", err)
+			}
+			if err.Error() != tt.want {
+				t.Errorf("loadJSON() error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{"line comment", "{\"a\": 1} // done", "{\"a\": 1}        ", false},
+		{"block comment keeps newlines", "/* x\ny */1", "    \n    1", false},
+		{"markers in strings", `{"u": "http://x/*y*/"}`, `{"u": "http://x/*y*/"}`, false},
+		{"escaped quote", `["a\"//", 1]`, `["a\"//", 1]`, false},
+		{"trailing commas", "[1, {\"a\": 2,},]", "[1, {\"a\": 2 } ]", false},
+		{"comma before comment", "[1, // c\n]", "[1      \n]", false},
+		{"unterminated comment", "1 /* x", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stripJSONC([]byte(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("stripJSONC() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && string(got) != tt.want {
+				t.Errorf("stripJSONC() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadYAMLNonStringKeys(t *testing.T) {
+	file := writeFile(t, "data.yml", "1: one\ntrue: yes\n")
+
+	data, err := loadJSON(file, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON() error = %v", err)
+	}
+	m, ok := data.(map[string]interface{})
+	if !ok {
+		t.Fatalf("loadJSON() = %T, want map[string]interface{}", data)
+	}
+	if m["1"] != "one" || m["true"] != "yes" {
+		t.Errorf("unexpected keys: %v", m)
+	}
+}
+
+func TestRunNot(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"a": 1}`)
+	matching := writeFile(t, "matching.json", `{"a": 1, "b": 2}`)
+	different := writeFile(t, "different.json", `{"b": 2}`)
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantStdout string
+		wantStderr string
+	}{
+		{
+			name:       "not a subset",
+			args:       []string{"--not", subsetFile, different},
+			wantCode:   exitSuccess,
+			wantStdout: "OK: First JSON is not a subset",
+		},
+		{
+			name:       "unexpectedly a subset",
+			args:       []string{"--not", subsetFile, matching},
+			wantCode:   exitFailure,
+			wantStderr: "unexpectedly a subset",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d", code, tt.wantCode)
+			}
+			if !strings.Contains(stdout.String(), tt.wantStdout) {
+				t.Errorf("stdout = %q, want it to contain %q", stdout.String(), tt.wantStdout)
+			}
+			if !strings.Contains(stderr.String(), tt.wantStderr) {
+				t.Errorf("stderr = %q, want it to contain %q", stderr.String(), tt.wantStderr)
+			}
+		})
+	}
+}
+
+func TestRunMultipleSupersets(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"id": 2}`)
+	shard1 := writeFile(t, "shard1.json", `{"id": 1}`)
+	shard2 := writeFile(t, "shard2.json", `{"id": 2, "name": "bob"}`)
+	shard3 := writeFile(t, "shard3.json", `{"id": 3}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{subsetFile, shard1, shard2, shard3}, &stdout, &stderr); code != exitSuccess {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), shard2) {
+		t.Errorf("stdout = %q, want it to name the matching file", stdout.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{subsetFile, shard1, shard3}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d", code, exitFailure)
+	}
+	for _, file := range []string{shard1, shard3} {
+		if !strings.Contains(stderr.String(), "--- "+file) {
+			t.Errorf("stderr should contain diffs for %s, got %q", file, stderr.String())
+		}
+	}
+}
+
+func TestRunNDJSON(t *testing.T) {
+	subsetFile := writeFile(t, "subset.ndjson", "{\"id\": 1}\n{\"id\": 2, \"level\": \"info\"}\n{\"id\": 3}\n")
+	supersetFile := writeFile(t, "superset.ndjson", "{\"id\": 1, \"msg\": \"a\"}\n{\"id\": 2, \"level\": \"error\"}\n")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--ndjson", subsetFile, supersetFile}, &stdout, &stderr)
+	if code != exitFailure {
+		t.Fatalf("run() = %d, want %d", code, exitFailure)
+	}
+
+	if !strings.Contains(stdout.String(), "OK: line 1") {
+		t.Errorf("stdout = %q, want line 1 to pass", stdout.String())
+	}
+	for _, want := range []string{"FAIL: line 2", "FAIL: line 3", "1 lines matched, 2 failed"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+		}
+	}
+}
+
+func TestLoadJSONLiteral(t *testing.T) {
+	data, err := loadJSON(`json:{"a": [1, "x"]}`, "auto")
+	if err != nil {
+		t.Fatalf("loadJSON() error = %v", err)
+	}
+	want := map[string]interface{}{"a": []interface{}{float64(1), "x"}}
+	if ok, _ := subset.CheckSubset(want, data); !ok {
+		t.Errorf("loadJSON() = %v, want %v", data, want)
+	}
+
+	if _, err := loadJSON("json:{", "auto"); err == nil {
+		t.Error("loadJSON() should fail on invalid literal")
+	}
+
+	supersetFile := writeFile(t, "superset.json", `{"a": 1, "b": 2}`)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{`json:{"a": 1}`, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Errorf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+}
+
+func TestRunLoadErrorCodes(t *testing.T) {
+	valid := writeFile(t, "valid.json", `{"a": 1}`)
+	invalid := writeFile(t, "invalid.json", `{"a": `)
+	invalidYAML := writeFile(t, "invalid.yaml", "a: [1")
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{"missing subset", []string{missing, valid}, exitError},
+		{"missing superset", []string{valid, missing}, exitError},
+		{"invalid subset", []string{invalid, valid}, exitParseError},
+		{"invalid superset", []string{valid, invalid}, exitParseError},
+		{"invalid yaml", []string{invalidYAML, valid}, exitParseError},
+		{"invalid literal", []string{"json:{", valid}, exitParseError},
+		{"invalid ndjson", []string{"--ndjson", valid, invalid}, exitParseError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunQuiet(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"a": 1}`)
+	matching := writeFile(t, "matching.json", `{"a": 1, "b": 2}`)
+	different := writeFile(t, "different.json", `{"a": 2}`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{"success", []string{"--quiet", subsetFile, matching}, exitSuccess},
+		{"failure", []string{"--quiet", subsetFile, different}, exitFailure},
+		{"shorthand", []string{"-q", subsetFile, different}, exitFailure},
+		{"json output", []string{"-q", "--output=json", subsetFile, different}, exitFailure},
+		{"not", []string{"-q", "--not", subsetFile, matching}, exitFailure},
+		{"ndjson", []string{"-q", "--ndjson", subsetFile, different}, exitFailure},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d", code, tt.wantCode)
+			}
+			if stdout.Len() != 0 || stderr.Len() != 0 {
+				t.Errorf("quiet mode wrote output: stdout %q, stderr %q", stdout.String(), stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunAt(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice"}`)
+	supersetFile := writeFile(t, "superset.json", `{"data": {"user": {"name": "alice", "age": 30}}}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--at", "$.data.user", subsetFile, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Errorf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+
+	stderr.Reset()
+	if code := run([]string{"--at", "$.data.group", subsetFile, supersetFile}, &stdout, &stderr); code != exitError {
+		t.Errorf("run() = %d, want %d", code, exitError)
+	}
+	if !strings.Contains(stderr.String(), "matches nothing") {
+		t.Errorf("stderr = %q, want a clear error", stderr.String())
+	}
+}
+
+func TestRunExplain(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice"}`)
+	supersetFile := writeFile(t, "superset.json", `{"name": "alice", "age": 30}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--explain", subsetFile, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"name": "alice" # ok`) {
+		t.Errorf("stdout = %q, want matched leaf marked", stdout.String())
+	}
+}
+
+func TestRunRequiredKeys(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "app"}`)
+	supersetFile := writeFile(t, "superset.json", `{"id": 1, "name": "app", "items": [{"id": 2}, {"value": 3}]}`)
+	keysFile := writeFile(t, "keys.json", `["id"]`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--required-keys", keysFile, subsetFile, supersetFile}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d", code, exitFailure)
+	}
+	if !strings.Contains(stderr.String(), `$['items'][1]['id']: required key "id" is missing`) {
+		t.Errorf("stderr = %q, want the missing required key", stderr.String())
+	}
+
+	badKeys := writeFile(t, "bad.json", `{"id": true}`)
+	stderr.Reset()
+	if code := run([]string{"--required-keys", badKeys, subsetFile, supersetFile}, &stdout, &stderr); code != exitError {
+		t.Errorf("run() = %d, want %d", code, exitError)
+	}
+}
+
+func TestRunStats(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice", "tags": ["a"]}`)
+	supersetFile := writeFile(t, "superset.json", `{"name": "alice", "tags": ["a", "b"]}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--stats", subsetFile, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+	if got := stderr.String(); got != "Stats: 2 object keys, 1 array elements, 2 primitive comparisons\n" {
+		t.Errorf("stderr = %q", got)
+	}
+}
+
+func gzipString(t *testing.T, content string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestLoadGzip(t *testing.T) {
+	content := `{"user": {"name": "alice"}, "tags": ["admin"]}`
+	plain, err := loadJSON(writeFile(t, "subset.json", content), "auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"subset.json.gz", "subset.json"} {
+		got, err := loadJSON(writeFile(t, name, gzipString(t, content)), "auto")
+		if err != nil {
+			t.Fatalf("loadJSON(%s) error: %v", name, err)
+		}
+		if ok, diffs := subset.CheckSubset(got, plain); !ok {
+			t.Errorf("loadJSON(%s) differs from the plain file: %+v", name, diffs)
+		}
+		if ok, _ := subset.CheckSubset(plain, got); !ok {
+			t.Errorf("loadJSON(%s) differs from the plain file", name)
+		}
+	}
+
+	yamlFile := writeFile(t, "subset.yaml.gz", gzipString(t, "user:\n  name: alice\n"))
+	if _, err := loadJSON(yamlFile, "auto"); err != nil {
+		t.Errorf("gzipped YAML should be detected by its inner extension: %v", err)
+	}
+}
+
+func TestRunGzip(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json.gz", gzipString(t, `{"name": "alice"}`))
+	supersetFile := writeFile(t, "superset.json", `{"name": "alice", "age": 30}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{subsetFile, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Errorf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+}
+
+func TestRunUnified(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice", "role": "admin"}`)
+	supersetFile := writeFile(t, "superset.json", `{"name": "alice", "role": "user"}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--output=unified", subsetFile, supersetFile}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitFailure, stderr.String())
+	}
+	want := "--- " + supersetFile + "\n+++ " + subsetFile + `
+@@ -1,4 +1,4 @@
+ {
+   "name": "alice",
+-  "role": "user"
++  "role": "admin"
+ }
+`
+	if stdout.String() != want {
+		t.Errorf("stdout =\n%s\nwant\n%s", stdout.String(), want)
+	}
+}
+
+func TestRunMerged(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice", "role": "admin"}`)
+	supersetFile := writeFile(t, "superset.json", `{"name": "alice", "role": "user"}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--output=merged", subsetFile, supersetFile}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitFailure, stderr.String())
+	}
+	want := `{
+  "name": "alice",
+  "role": {
+    "__subset__": "admin",
+    "__superset__": "user"
+  }
+}
+`
+	if stdout.String() != want {
+		t.Errorf("stdout =\n%s\nwant\n%s", stdout.String(), want)
+	}
+}
+
+func TestRunCombined(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		args     []string
+		wantCode int
+		wantOut  string
+	}{
+		{
+			name:     "subset",
+			content:  `{"description": "user fields", "subset": {"name": "alice"}, "superset": {"name": "alice", "age": 30}}`,
+			wantCode: exitSuccess,
+			wantOut:  "OK: First JSON is a subset of second JSON.\n",
+		},
+		{
+			name:     "not a subset",
+			content:  `{"subset": {"role": "admin"}, "superset": {"role": "user"}}`,
+			args:     []string{"--output=json"},
+			wantCode: exitFailure,
+		},
+		{
+			name:     "options apply",
+			content:  `{"subset": {"role": "ADMIN"}, "superset": {"role": "admin"}}`,
+			args:     []string{"--ignore-case"},
+			wantCode: exitSuccess,
+			wantOut:  "OK: First JSON is a subset of second JSON.\n",
+		},
+		{
+			name:     "missing superset",
+			content:  `{"subset": {}}`,
+			wantCode: exitParseError,
+		},
+		{
+			name:     "not an object",
+			content:  `[1, 2]`,
+			wantCode: exitParseError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := writeFile(t, "case.json", tt.content)
+			var stdout, stderr bytes.Buffer
+			code := run(append(append([]string{"--combined"}, tt.args...), file), &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if tt.wantOut != "" && stdout.String() != tt.wantOut {
+				t.Errorf("stdout = %q, want %q", stdout.String(), tt.wantOut)
+			}
+		})
+	}
+
+	// Diffs are reported against the halves of the file.
+	file := writeFile(t, "case.json", `{"subset": {"role": "admin"}, "superset": {"role": "user"}}`)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--combined", "--output=unified", file}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitFailure, stderr.String())
+	}
+	if want := "--- " + file + "#superset\n+++ " + file + "#subset\n"; !strings.HasPrefix(stdout.String(), want) {
+		t.Errorf("stdout =\n%s\nwant it to start with\n%s", stdout.String(), want)
+	}
+
+	if code := run([]string{"--combined", file, file}, &stdout, &stderr); code != exitError {
+		t.Errorf("run() with two files = %d, want %d", code, exitError)
+	}
+}
+
+func TestRunTAP(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice"}`)
+	passing := writeFile(t, "passing.json", `{"name": "alice", "age": 30}`)
+	failing := writeFile(t, "failing.json", `{"name": "bob"}`)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		want     string
+	}{
+		{"pass", []string{subsetFile, passing}, exitSuccess, "ok 1 - " + subsetFile + " is a subset of " + passing + "\n"},
+		{"fail", []string{subsetFile, failing}, exitFailure, "not ok 1 - " + subsetFile + " is a subset of " + failing + "\n  ---\n"},
+		{"not", []string{"--not", subsetFile, failing}, exitSuccess, "ok 1 - " + subsetFile + " is not a subset of " + failing + "\n  ---\n"},
+		{"several supersets", []string{"--match-mode=all", subsetFile, passing, failing}, exitFailure, "not ok 1 - " + subsetFile + " is a subset of all of " + passing + ", " + failing + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run(append([]string{"--output=tap"}, tt.args...), &stdout, &stderr); code != tt.wantCode {
+				t.Fatalf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if want := "TAP version 13\n1..1\n" + tt.want; !strings.HasPrefix(stdout.String(), want) {
+				t.Errorf("stdout =\n%s\nwant it to start with\n%s", stdout.String(), want)
+			}
+		})
+	}
+}
+
+func TestRunRules(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"price": 9.99, "id": "re:/^u[0-9]+$/", "name": "Alice"}`)
+	supersetFile := writeFile(t, "superset.json", `{"price": 9.991, "id": "u42", "name": "Alice"}`)
+	rulesFile := writeFile(t, "rules.json", `{"$.price": "epsilon:0.01", "$.id": "regex"}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--rules", rulesFile, subsetFile, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Errorf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+
+	badRules := writeFile(t, "bad.json", `{"$.price": "fuzzy"}`)
+	stderr.Reset()
+	if code := run([]string{"--rules", badRules, subsetFile, supersetFile}, &stdout, &stderr); code != exitError {
+		t.Errorf("run() = %d, want %d", code, exitError)
+	}
+	if !strings.Contains(stderr.String(), "unknown directive") {
+		t.Errorf("stderr = %q, want the invalid directive", stderr.String())
+	}
+}
+
+func TestRejectDuplicateKeys(t *testing.T) {
+	file := writeFile(t, "dup.json", `{"user": {"name": "alice", "tags": [{"id": 1, "id": 2}]}}`)
+
+	if _, err := loadJSON(file, "auto"); err != nil {
+		t.Fatalf("duplicates should be accepted by default: %v", err)
+	}
+
+	_, err := loadInput(file, inputOptions{format: "auto", rejectDuplicateKeys: true})
+	if err == nil {
+		t.Fatal("loadInput() should reject duplicate keys")
+	}
+	if want := `duplicate key "id" in $['user']['tags'][0]`; !strings.Contains(err.Error(), want) {
+		t.Errorf("error = %v, want %q", err, want)
+	}
+	if code := loadExitCode(err); code != exitParseError {
+		t.Errorf("loadExitCode() = %d, want %d", code, exitParseError)
+	}
+
+	unique := writeFile(t, "unique.json", `{"a": {"id": 1}, "b": {"id": 2}, "c": [1, {"x": null}]}`)
+	if _, err := loadInput(unique, inputOptions{format: "auto", rejectDuplicateKeys: true}); err != nil {
+		t.Errorf("the same key in different objects is not a duplicate: %v", err)
+	}
+}
+
+func TestRunLineNumbers(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"user": {"name": "alice", "email": "a@example.com"}}`)
+	supersetFile := writeFile(t, "superset.json", `{
+  "id": 1,
+  "user": {
+    "name": "bob"
+  }
+}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--line-numbers", subsetFile, supersetFile}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d", code, exitFailure)
+	}
+	for _, want := range []string{
+		`"email": "a@example.com", (superset line 3)`,
+		`"name": "alice" (superset: "bob", line 4)`,
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr = %q, want %q", stderr.String(), want)
+		}
+	}
+
+	// With --at the lines still refer to the whole superset file.
+	atSubset := writeFile(t, "at.json", `{"name": "alice"}`)
+	stderr.Reset()
+	run([]string{"--line-numbers", "--at", "$.user", atSubset, supersetFile}, &stdout, &stderr)
+	if !strings.Contains(stderr.String(), `(superset: "bob", line 4)`) {
+		t.Errorf("stderr = %q, want line 4", stderr.String())
+	}
+}
+
+func TestRunPreserveKeyOrder(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"zeta": 1, "alpha": {"y": 2, "x": 3}, "mid": 4}`)
+	supersetFile := writeFile(t, "superset.json", `{"zeta": 1, "alpha": {"y": 2, "x": 3}}`)
+
+	var stdout, stderr bytes.Buffer
+	run([]string{"--preserve-key-order", subsetFile, supersetFile}, &stdout, &stderr)
+	want := ` {
+   "zeta": 1,
+   "alpha": {
+     "y": 2,
+     "x": 3
+   },
+-  "mid": 4
+ }
+`
+	if !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr =\n%s\nwant\n%s", stderr.String(), want)
+	}
+
+	stderr.Reset()
+	run([]string{subsetFile, supersetFile}, &stdout, &stderr)
+	if !strings.Contains(stderr.String(), " {\n   \"alpha\": {\n     \"x\": 3,") {
+		t.Errorf("keys should be sorted by default, got:\n%s", stderr.String())
+	}
+}
+
+func TestRunParallel(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"a": {"x": 1}, "b": [1, 2], "c": "z", "d": true}`)
+	supersetFile := writeFile(t, "superset.json", `{"a": {"x": 2}, "b": [2], "c": "z", "d": true}`)
+
+	var seqOut, seqErr, parOut, parErr bytes.Buffer
+	seqCode := run([]string{subsetFile, supersetFile}, &seqOut, &seqErr)
+	parCode := run([]string{"--parallel=0", subsetFile, supersetFile}, &parOut, &parErr)
+	if seqCode != parCode || seqErr.String() != parErr.String() {
+		t.Errorf("parallel output differs:\n%s\nwant\n%s", parErr.String(), seqErr.String())
+	}
+}
+
+func TestRunURLSuperset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/user.json":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name": "alice", "age": 30}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice"}`)
+
+	tests := []struct {
+		name     string
+		url      string
+		wantCode int
+	}{
+		{"subset", server.URL + "/user.json", exitSuccess},
+		{"query string", server.URL + "/user.json?id=1", exitSuccess},
+		{"not found", server.URL + "/missing.json", exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if code := run([]string{"--timeout=5s", subsetFile, tt.url}, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunURLSupersetRetry(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := requests.Add(1)
+		switch {
+		case r.URL.Path == "/missing.json":
+			http.NotFound(w, r)
+		case n <= 2:
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+		default:
+			fmt.Fprint(w, `{"name": "alice", "age": 30}`)
+		}
+	}))
+	defer server.Close()
+
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice"}`)
+	tests := []struct {
+		name         string
+		args         []string
+		path         string
+		wantCode     int
+		wantRequests int32
+	}{
+		{"fails twice then succeeds", []string{"--retry=2"}, "/user.json", exitSuccess, 3},
+		{"too few retries", []string{"--retry=1"}, "/user.json", exitError, 2},
+		{"no retries by default", nil, "/user.json", exitError, 1},
+		{"client errors are not retried", []string{"--retry=3"}, "/missing.json", exitError, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+			var stdout, stderr bytes.Buffer
+			args := append(append([]string{"--retry-delay=1ms"}, tt.args...), subsetFile, server.URL+tt.path)
+			if code := run(args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestRunDisallowEmpty(t *testing.T) {
+	supersetFile := writeFile(t, "superset.json", `{"name": "alice"}`)
+
+	tests := []struct {
+		name     string
+		subset   string
+		wantCode int
+	}{
+		{"empty object", `{}`, exitEmpty},
+		{"empty array", `[]`, exitEmpty},
+		{"null", `null`, exitEmpty},
+		{"non-empty", `{"name": "alice"}`, exitSuccess},
+		{"zero value", `0`, exitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subsetFile := writeFile(t, "subset.json", tt.subset)
+			var stdout, stderr bytes.Buffer
+			if code := run([]string{"--disallow-empty", subsetFile, supersetFile}, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+		})
+	}
+
+	// Without the flag an empty subset matches anything.
+	subsetFile := writeFile(t, "subset.json", `{}`)
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{subsetFile, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Errorf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+}
+
+func TestRunSwap(t *testing.T) {
+	small := writeFile(t, "small.json", `{"name": "alice", "role": "admin"}`)
+	large := writeFile(t, "large.json", `{"name": "alice", "role": "user", "age": 30}`)
+
+	var stdout, stderr, swappedOut, swappedErr bytes.Buffer
+	code := run([]string{small, large}, &stdout, &stderr)
+	swappedCode := run([]string{"--swap", large, small}, &swappedOut, &swappedErr)
+	if code != exitFailure || swappedCode != code {
+		t.Errorf("run(--swap) = %d, run() = %d, want both %d", swappedCode, code, exitFailure)
+	}
+	if swappedOut.String() != stdout.String() || swappedErr.String() != stderr.String() {
+		t.Errorf("--swap output differs:\n%s\nwant\n%s", swappedErr.String(), stderr.String())
+	}
+
+	if code := run([]string{"--swap", large, small, small}, &stdout, &stderr); code != exitError {
+		t.Errorf("run(--swap) with three files = %d, want %d", code, exitError)
+	}
+}
+
+func TestRunGlobSupersets(t *testing.T) {
+	dir := t.TempDir()
+	writeFileIn(t, dir, "a.json", `{"name": "alice", "role": "user"}`)
+	writeFileIn(t, dir, "b.json", `{"name": "alice", "role": "admin"}`)
+	writeFileIn(t, dir, "c.json", `{"name": "alice", "role": "admin", "age": 30}`)
+	writeFileIn(t, dir, "notes.txt", `not json`)
+	subsetFile := writeFile(t, "subset.json", `{"role": "admin"}`)
+	pattern := filepath.Join(dir, "*.json")
+
+	tests := []struct {
+		name       string
+		args       []string
+		wantCode   int
+		wantOutput string
+	}{
+		{"any", []string{subsetFile, pattern}, exitSuccess, "OK: First JSON is a subset of " + filepath.Join(dir, "b.json") + "."},
+		{"all", []string{"--match-mode=all", subsetFile, pattern}, exitFailure, "FAIL: First JSON is not a subset of 1 of the 3 files."},
+		{"all passing", []string{"--match-mode=all", subsetFile, filepath.Join(dir, "[bc].json")}, exitSuccess, "OK: First JSON is a subset of all 2 files."},
+		{"no match", []string{subsetFile, filepath.Join(dir, "*.yaml")}, exitError, "no files match"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			code := run(tt.args, &stdout, &stderr)
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if out := stdout.String() + stderr.String(); !strings.Contains(out, tt.wantOutput) {
+				t.Errorf("output = %q, want it to contain %q", out, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestRunMap(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"user": {"userName": "alice"}}`)
+	supersetFile := writeFile(t, "superset.json", `{"user": {"username": "alice", "id": 1}}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{subsetFile, supersetFile}, &stdout, &stderr); code != exitFailure {
+		t.Errorf("run() without --map = %d, want %d", code, exitFailure)
+	}
+	if code := run([]string{"--map=$.user.userName=username", subsetFile, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Errorf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+	if code := run([]string{"--map=userName", subsetFile, supersetFile}, &stdout, &stderr); code != exitError {
+		t.Errorf("run() with an invalid mapping = %d, want %d", code, exitError)
+	}
+}
+
+// benchmarkDocs writes a subset and superset with n branches for the
+// benchmarks comparing file loading with subset.CheckSubsetBytes
+func benchmarkDocs(b *testing.B, n int) ([]byte, []byte) {
+	b.Helper()
+	subsetDoc := make(map[string]interface{}, n)
+	supersetDoc := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("key%03d", i)
+		subsetDoc[key] = map[string]interface{}{"id": i, "tags": []string{"a", "b"}}
+		supersetDoc[key] = map[string]interface{}{"id": i, "tags": []string{"b", "a", "c"}, "extra": true}
+	}
+	subsetData, err := json.Marshal(subsetDoc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	supersetData, err := json.Marshal(supersetDoc)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return subsetData, supersetData
+}
+
+func BenchmarkLoadFilesAndCheck(b *testing.B) {
+	subsetData, supersetData := benchmarkDocs(b, 500)
+	dir := b.TempDir()
+	subsetFile := filepath.Join(dir, "subset.json")
+	supersetFile := filepath.Join(dir, "superset.json")
+	if err := os.WriteFile(subsetFile, subsetData, 0o644); err != nil {
+		b.Fatal(err)
+	}
+	if err := os.WriteFile(supersetFile, supersetData, 0o644); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		subsetDoc, err := loadJSON(subsetFile, "auto")
+		if err != nil {
+			b.Fatal(err)
+		}
+		supersetDoc, err := loadJSON(supersetFile, "auto")
+		if err != nil {
+			b.Fatal(err)
+		}
+		subset.CheckSubset(subsetDoc, supersetDoc)
+	}
+}
+
+func BenchmarkCheckSubsetBytes(b *testing.B) {
+	subsetData, supersetData := benchmarkDocs(b, 500)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := subset.CheckSubsetBytes(subsetData, supersetData, subset.Options{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestRunGitHub(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice", "role": "admin", "version": "1.0"}`)
+	supersetFile := writeFile(t, "superset.json", "{\n  \"name\": \"alice\",\n  \"role\": \"user\"\n}\n")
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--output=github", "--line-numbers", subsetFile, supersetFile}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitFailure, stderr.String())
+	}
+	want := "::error file=" + supersetFile + ",line=3,title=value mismatch::$['role']: want \"admin\", got \"user\"\n" +
+		"::error file=" + supersetFile + ",line=1,title=missing key::$['version']: missing from superset, want \"1.0\"\n"
+	if stdout.String() != want {
+		t.Errorf("stdout =\n%s\nwant\n%s", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	if code := run([]string{"--output=github", subsetFile, `json:{"name": "bob"}`}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d", code, exitFailure)
+	}
+	if strings.Contains(stdout.String(), "file=") || !strings.HasPrefix(stdout.String(), "::error title=value mismatch::$['name']") {
+		t.Errorf("inline superset should have no file property, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunLargeIntegers(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"id": 1234567890123456789, "tags": [9007199254740993]}`)
+
+	tests := []struct {
+		name     string
+		superset string
+		wantCode int
+	}{
+		{"same", `{"id": 1234567890123456789, "tags": [1, 9007199254740993]}`, exitSuccess},
+		{"id off by one", `{"id": 1234567890123456788, "tags": [9007199254740993]}`, exitFailure},
+		{"element off by one", `{"id": 1234567890123456789, "tags": [9007199254740992]}`, exitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			supersetFile := writeFile(t, "superset.json", tt.superset)
+			var stdout, stderr bytes.Buffer
+			if code := run([]string{subsetFile, supersetFile}, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+		})
+	}
+}
+
+// withStdin makes os.Stdin read content for the rest of the test
+func withStdin(t *testing.T, content string) {
+	t.Helper()
+	f, err := os.Open(writeFile(t, "stdin", content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdin
+	os.Stdin = f
+	t.Cleanup(func() {
+		os.Stdin = old
+		f.Close()
+	})
+}
+
+func TestRunStdin(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "alice"}`)
+	supersetFile := writeFile(t, "superset.json", `{"name": "alice", "age": 30}`)
+
+	tests := []struct {
+		name     string
+		stdin    string
+		args     []string
+		wantCode int
+	}{
+		{"subset from stdin", `{"name": "alice"}`, []string{"-", supersetFile}, exitSuccess},
+		{"superset from stdin", `{"name": "alice", "age": 30}`, []string{subsetFile, "-"}, exitSuccess},
+		{"superset from stdin fails", `{"name": "bob"}`, []string{subsetFile, "-"}, exitFailure},
+		{"both from stdin", `{}`, []string{"-", "-"}, exitError},
+		{"superset listed twice", `{}`, []string{subsetFile, "-", "-"}, exitError},
+		{"rules and superset from stdin", `{}`, []string{"--rules=-", subsetFile, "-"}, exitError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withStdin(t, tt.stdin)
+			var stdout, stderr bytes.Buffer
+			if code := run(tt.args, &stdout, &stderr); code != tt.wantCode {
+				t.Errorf("run() = %d, want %d; stderr: %s", code, tt.wantCode, stderr.String())
+			}
+			if tt.wantCode == exitError && !strings.Contains(stderr.String(), "only one input can be read from stdin") {
+				t.Errorf("stderr = %q, want the stdin error", stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunTable(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"name": "myapp", "license": "MIT"}`)
+	supersetFile := writeFile(t, "superset.json", `{"name": "other"}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--output=table", subsetFile, supersetFile}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitFailure, stderr.String())
+	}
+	want := `PATH          TYPE            SUBSET   SUPERSET
+$['license']  missing_key     "MIT"    -
+$['name']     value_mismatch  "myapp"  "other"
+`
+	if stdout.String() != want {
+		t.Errorf("stdout =\n%s\nwant\n%s", stdout.String(), want)
+	}
+}
+
+func TestRunExtract(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"user": {"name": "bob", "email": "x"}, "tags": ["ops"]}`)
+	supersetFile := writeFile(t, "superset.json", `{"user": {"name": "alice", "age": 30}, "tags": ["dev", "ops"], "id": 12345678901234567890}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--extract", subsetFile, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Fatalf("run(--extract) = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+	var got interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatalf("--extract output is not JSON: %v\n%s", err, stdout.String())
+	}
+	want := map[string]interface{}{
+		"user": map[string]interface{}{"name": "alice"},
+		"tags": []interface{}{"ops"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("--extract printed %v, want %v", got, want)
+	}
+
+	if code := run([]string{"--extract", subsetFile, supersetFile, supersetFile}, &stdout, &stderr); code != exitError {
+		t.Errorf("run(--extract) with two supersets = %d, want %d", code, exitError)
+	}
+}
+
+func TestRunSelfCheck(t *testing.T) {
+	docs := []string{
+		`{"name": "alice", "middleName": null, "tags": ["a", "b", "a"], "nested": {"empty": {}, "list": []}}`,
+		`[{"id": 1, "items": [[1, 2], [2, 1]]}, {"id": 2, "items": null}, null, "x", 3.5]`,
+		`{"users": [{"id": 1, "roles": ["admin"]}, {"id": 1, "roles": ["admin"]}, {"id": 2}]}`,
+		`{"big": 12345678901234567890, "small": 1e-10, "flag": true, "text": " Mixed Case "}`,
+		`null`,
+		`"just a string"`,
+		`[]`,
+	}
+	var files []string
+	for i, doc := range docs {
+		files = append(files, writeFile(t, fmt.Sprintf("doc%d.json", i), doc))
+	}
+
+	optionSets := [][]string{
+		nil,
+		{"--array-order=ordered"},
+		{"--array-order=multiset", "--array-exact-length"},
+		{"--array-key=id"},
+		{"--ignore-case", "--trim-strings", "--coerce-bool", "--epsilon=0.1"},
+		{"--null-means-optional", "--intersection", "--show-extra"},
+		{"--parallel=4", "--max-depth=1"},
+	}
+	for _, options := range optionSets {
+		var stdout, stderr bytes.Buffer
+		args := append(append(append([]string{}, options...), "--self-check"), files...)
+		if code := run(args, &stdout, &stderr); code != exitSuccess {
+			t.Errorf("run(%v --self-check) = %d, want %d; stderr:\n%s", options, code, exitSuccess, stderr.String())
+		}
+		if got := strings.Count(stdout.String(), "is a subset of itself"); got != len(files) {
+			t.Errorf("run(%v --self-check) reported %d files, want %d", options, got, len(files))
+		}
+	}
+
+	// Regular expressions give subset strings a meaning a literal does not match.
+	var stdout, stderr bytes.Buffer
+	regex := writeFile(t, "regex.json", `{"id": "re:/^[0-9]+$/"}`)
+	if code := run([]string{"--enable-regex", "--self-check", regex}, &stdout, &stderr); code != exitFailure {
+		t.Errorf("run(--self-check) with a regex = %d, want %d", code, exitFailure)
+	}
+	if !strings.Contains(stderr.String(), "1 of 1 files failed the self-check") {
+		t.Errorf("stderr = %q, want a failure summary", stderr.String())
+	}
+}
+
+func TestRunSubsets(t *testing.T) {
+	superset := writeFile(t, "superset.json", `{"name": "alice", "role": "admin", "tags": ["a", "b"]}`)
+	nameFragment := writeFile(t, "name.json", `{"name": "alice"}`)
+	tagsFragment := writeFile(t, "tags.json", `{"tags": ["b"]}`)
+	roleFragment := writeFile(t, "role.json", `{"role": "user"}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--subsets", nameFragment, tagsFragment, superset}, &stdout, &stderr); code != exitSuccess {
+		t.Fatalf("run(--subsets) = %d, want %d; stderr:\n%s", code, exitSuccess, stderr.String())
+	}
+	if got := strings.Count(stdout.String(), "PASS: "); got != 2 {
+		t.Errorf("stdout = %q, want two PASS lines", stdout.String())
+	}
+	if !strings.Contains(stderr.String(), "2 passed, 0 failed") {
+		t.Errorf("stderr = %q, want a summary", stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"--subsets", nameFragment, roleFragment, superset}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run(--subsets) with a failing fragment = %d, want %d", code, exitFailure)
+	}
+	for _, want := range []string{"FAIL: " + roleFragment, `"role": "user" (superset: "admin")`, "1 passed, 1 failed"} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr = %q, want it to contain %q", stderr.String(), want)
+		}
+	}
+}
+
+func TestRunSortArrays(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"ids": [3, 1, 2], "items": [{"id": 1}]}`)
+	supersetFile := writeFile(t, "superset.json", `{"ids": [1, 2, 3], "items": [{"id": 1}]}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--array-order=ordered", subsetFile, supersetFile}, &stdout, &stderr); code != exitFailure {
+		t.Errorf("run() without --sort-arrays = %d, want %d", code, exitFailure)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"--sort-arrays", "--array-order=ordered", subsetFile, supersetFile}, &stdout, &stderr); code != exitSuccess {
+		t.Fatalf("run(--sort-arrays) = %d, want %d; stderr:\n%s", code, exitSuccess, stderr.String())
+	}
+	if got := strings.Count(stderr.String(), "array at $['items'] is not sorted"); got != 2 {
+		t.Errorf("stderr = %q, want a warning for each file", stderr.String())
+	}
+}
+
+func TestRunSchema(t *testing.T) {
+	schema := writeFile(t, "schema.json", `{"type": "object", "required": ["id"], "properties": {"id": {"type": "integer"}}}`)
+	valid := writeFile(t, "valid.json", `{"id": 7, "name": "alice"}`)
+	invalid := writeFile(t, "invalid.json", `{"id": "7"}`)
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--schema", schema, valid}, &stdout, &stderr); code != exitSuccess {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitSuccess, stderr.String())
+	}
+	if want := "OK: " + valid + " matches the schema\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := run([]string{"--schema", schema, valid, invalid}, &stdout, &stderr); code != exitFailure {
+		t.Fatalf("run() = %d, want %d; stderr: %s", code, exitFailure, stderr.String())
+	}
+	for _, want := range []string{
+		"FAIL: " + invalid + " does not match the schema\n",
+		`-  "id": "7" (superset: {"type":"integer"})`,
+		"1 difference found (1 type mismatch)",
+	} {
+		if !strings.Contains(stderr.String(), want) {
+			t.Errorf("stderr missing %q:\n%s", want, stderr.String())
+		}
+	}
+
+	badSchema := writeFile(t, "bad.json", `{"type": "float"}`)
+	if code := run([]string{"--schema", badSchema, valid}, &stdout, &stderr); code != exitError {
+		t.Errorf("run() with an invalid schema = %d, want %d", code, exitError)
+	}
+}
+
+func TestRunPathStyle(t *testing.T) {
+	subsetFile := writeFile(t, "subset.json", `{"items": [{"id": 1}]}`)
+	supersetFile := writeFile(t, "superset.json", `{"items": [{"id": 2}]}`)
+
+	tests := []struct {
+		style string
+		want  string
+	}{
+		{"jsonpath", `$['items'][0]['id']: 1 != 2`},
+		{"pointer", `/items/0/id: 1 != 2`},
+		{"dotted", `items.0.id: 1 != 2`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.style, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			args := []string{"--path-style=" + tt.style, "--diff-only-values", "--array-order=ordered", subsetFile, supersetFile}
+			if code := run(args, &stdout, &stderr); code != exitFailure {
+				t.Fatalf("run() = %d, want %d; stderr: %s", code, exitFailure, stderr.String())
+			}
+			if !strings.Contains(stderr.String(), tt.want) {
+				t.Errorf("stderr =\n%s\nwant it to contain %q", stderr.String(), tt.want)
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"--path-style=slash", subsetFile, supersetFile}, &stdout, &stderr); code != exitError {
+		t.Errorf("run() = %d, want %d", code, exitError)
+	}
+}
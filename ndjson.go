@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zinrai/json-subset/subset"
+)
+
+// runNDJSON checks each subset line against the superset line at the same
+// position. Both files are streamed, so they are never loaded whole.
+// In quiet mode only load errors are printed.
+func runNDJSON(subsetFile, supersetFile string, opts subset.Options, formatOpts subset.FormatOptions, quiet bool, stdout, stderr io.Writer) int {
+	report, reportErr := stdout, stderr
+	if quiet {
+		report, reportErr = io.Discard, io.Discard
+	}
+
+	subsetIn, err := openInput(subsetFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading %s: %v\n", subsetFile, err)
+		return exitError
+	}
+	defer subsetIn.Close()
+
+	supersetIn, err := openInput(supersetFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading %s: %v\n", supersetFile, err)
+		return exitError
+	}
+	defer supersetIn.Close()
+
+	subsetDec := json.NewDecoder(bufio.NewReader(subsetIn))
+	supersetDec := json.NewDecoder(bufio.NewReader(supersetIn))
+	subsetDec.UseNumber()
+	supersetDec.UseNumber()
+
+	matched, failed := 0, 0
+	for line := 1; ; line++ {
+		var subsetData, supersetData interface{}
+
+		err := subsetDec.Decode(&subsetData)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading %s line %d: %v\n", subsetFile, line, err)
+			return loadExitCode(decodeError(err))
+		}
+
+		err = supersetDec.Decode(&supersetData)
+		if err == io.EOF {
+			failed++
+			fmt.Fprintf(reportErr, "FAIL: line %d: no corresponding line in %s\n", line, supersetFile)
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading %s line %d: %v\n", supersetFile, line, err)
+			return loadExitCode(decodeError(err))
+		}
+
+		isSubset, diffs := subset.CheckSubsetWithOptions(subsetData, supersetData, opts)
+		if isSubset {
+			matched++
+			fmt.Fprintf(report, "OK: line %d\n", line)
+			continue
+		}
+
+		failed++
+		fmt.Fprintf(reportErr, "FAIL: line %d\n", line)
+		fmt.Fprint(reportErr, subset.FormatDiffOutputWithOptions(subsetData, diffs, formatOpts))
+	}
+
+	fmt.Fprintf(reportErr, "%d lines matched, %d failed\n", matched, failed)
+	if failed > 0 {
+		return exitFailure
+	}
+	return exitSuccess
+}
+
+// decodeError marks json.Decoder errors caused by invalid input as parse errors
+func decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return &parseError{err}
+	}
+	return err
+}
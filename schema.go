@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zinrai/json-subset/subset"
+)
+
+// runSchema validates each file against a JSON Schema, which is loaded
+// once. Only the type, required and enum keywords are checked. In quiet
+// mode only load and schema errors are printed.
+func runSchema(files []string, schemaFile string, in inputOptions, formatOpts subset.FormatOptions, quiet bool, stdout, stderr io.Writer) int {
+	report, reportErr := stdout, stderr
+	if quiet {
+		report, reportErr = io.Discard, io.Discard
+	}
+
+	schema, err := loadInput(schemaFile, in)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading %s: %v\n", schemaFile, err)
+		return loadExitCode(err)
+	}
+
+	failed := 0
+	for _, file := range files {
+		doc, err := loadInput(file, in)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading %s: %v\n", file, err)
+			return loadExitCode(err)
+		}
+
+		valid, diffs, err := subset.CheckSchema(doc, schema)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: invalid schema %s: %v\n", schemaFile, err)
+			return exitError
+		}
+		if valid {
+			fmt.Fprintf(report, "OK: %s matches the schema\n", file)
+			continue
+		}
+		failed++
+		fmt.Fprintf(reportErr, "FAIL: %s does not match the schema\n", file)
+		fmt.Fprint(reportErr, subset.FormatDiffOutputWithOptions(doc, diffs, formatOpts))
+		fmt.Fprintln(reportErr, subset.FormatDiffSummary(diffs))
+	}
+
+	if failed > 0 {
+		return exitFailure
+	}
+	return exitSuccess
+}
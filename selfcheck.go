@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zinrai/json-subset/subset"
+)
+
+// runSelfCheck checks that each file is a subset of itself. Every document
+// contains itself, so a failure means the current options break
+// reflexivity, which is worth a bug report. In quiet mode only load errors
+// are printed.
+func runSelfCheck(files []string, in inputOptions, opts subset.Options, formatOpts subset.FormatOptions, quiet bool, stdout, stderr io.Writer) int {
+	report, reportErr := stdout, stderr
+	if quiet {
+		report, reportErr = io.Discard, io.Discard
+	}
+
+	failed := 0
+	for _, file := range files {
+		doc, err := loadInput(file, in)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading %s: %v\n", file, err)
+			return loadExitCode(err)
+		}
+
+		isSubset, diffs := subset.CheckSubsetWithOptions(doc, doc, opts)
+		if isSubset {
+			fmt.Fprintf(report, "OK: %s is a subset of itself\n", file)
+			continue
+		}
+		failed++
+		fmt.Fprintf(reportErr, "FAIL: %s is not a subset of itself\n", file)
+		fmt.Fprint(reportErr, subset.FormatDiffOutputWithOptions(doc, diffs, formatOpts))
+		fmt.Fprintln(reportErr, subset.FormatDiffSummary(diffs))
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(reportErr, "%d of %d files failed the self-check; unless an option such as --enable-regex gives subset values a special meaning, this is a bug\n", failed, len(files))
+		return exitFailure
+	}
+	return exitSuccess
+}
@@ -0,0 +1,74 @@
+package subset
+
+import (
+	"strconv"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// checkArrayAsObject compares an array on one side with an object whose
+// keys are all array indexes ("0", "1", ...) on the other, as some encoders
+// write sparse arrays. Element i is paired with key "i". handled is false
+// for any other combination of types.
+func checkArrayAsObject(subset, superset interface{}, path spec.NormalizedPath, opts Options) (ok bool, diffs []Diff, handled bool) {
+	switch sub := subset.(type) {
+	case map[string]interface{}:
+		sup, isArr := superset.([]interface{})
+		if !isArr || !indexKeyed(sub) {
+			return false, nil, false
+		}
+		asObject := make(map[string]interface{}, len(sup))
+		for i, elem := range sup {
+			asObject[strconv.Itoa(i)] = elem
+		}
+		ok, diffs = checkSubsetPath(sub, asObject, path, opts)
+		return ok, diffs, true
+
+	case []interface{}:
+		sup, isMap := superset.(map[string]interface{})
+		if !isMap || !indexKeyed(sup) {
+			return false, nil, false
+		}
+		if opts.Stats != nil {
+			opts.Stats.ArrayElements += len(sub)
+		}
+		opts.progress.add(len(sub))
+
+		isSubset := true
+		for i, subsetElem := range sub {
+			if opts.stop(isSubset) {
+				break
+			}
+			childPath := append(copyPath(path), spec.Index(i))
+			if opts.ignored.contains(childPath) {
+				continue
+			}
+			supersetElem, exists := sup[strconv.Itoa(i)]
+			if !exists {
+				isSubset = false
+				diffs = append(diffs, Diff{Path: childPath, Type: DiffElementNotFound, SubsetValue: subsetElem})
+				opts.stream.emit(diffs)
+				continue
+			}
+			ok, childDiffs := checkSubsetPath(subsetElem, supersetElem, childPath, opts)
+			if !ok {
+				isSubset = false
+			}
+			diffs = append(diffs, childDiffs...)
+		}
+		return isSubset, diffs, true
+	}
+	return false, nil, false
+}
+
+// indexKeyed reports whether every key of obj is an array index written
+// in canonical form, such as "0" or "12" but not "01" or "-1"
+func indexKeyed(obj map[string]interface{}) bool {
+	for key := range obj {
+		n, err := strconv.Atoi(key)
+		if err != nil || n < 0 || strconv.Itoa(n) != key {
+			return false
+		}
+	}
+	return true
+}
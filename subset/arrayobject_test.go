@@ -0,0 +1,44 @@
+package subset
+
+import "testing"
+
+func TestArrayAsObject(t *testing.T) {
+	tests := []struct {
+		name      string
+		subset    interface{}
+		superset  interface{}
+		wantOK    bool
+		wantPaths []string
+	}{
+		{"object against array", map[string]interface{}{"0": "a", "1": "b"}, []interface{}{"a", "b"}, true, nil},
+		{"array against object", []interface{}{"a", "b"}, map[string]interface{}{"0": "a", "1": "b", "2": "c"}, true, nil},
+		{"sparse object", map[string]interface{}{"2": "c"}, []interface{}{"a", "b", "c"}, true, nil},
+		{"elements are aligned by index", []interface{}{"b", "a"}, map[string]interface{}{"0": "a", "1": "b"}, false, []string{"$[0]", "$[1]"}},
+		{"missing index", []interface{}{"a", "b"}, map[string]interface{}{"0": "a"}, false, []string{"$[1]"}},
+		{"index beyond the array", map[string]interface{}{"5": "f"}, []interface{}{"a"}, false, []string{"$['5']"}},
+		{"nested objects are compared", map[string]interface{}{"0": map[string]interface{}{"id": float64(1)}}, []interface{}{map[string]interface{}{"id": float64(1), "x": true}}, true, nil},
+		{"non-index keys are a type mismatch", map[string]interface{}{"0": "a", "01": "b"}, []interface{}{"a", "b"}, false, []string{"$"}},
+		{"negative keys are a type mismatch", []interface{}{"a"}, map[string]interface{}{"-1": "a"}, false, []string{"$"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, Options{ArrayAsObject: true})
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if len(diffs) != len(tt.wantPaths) {
+				t.Fatalf("got %d diffs, want %d: %+v", len(diffs), len(tt.wantPaths), diffs)
+			}
+			for i, d := range diffs {
+				if d.Path.String() != tt.wantPaths[i] {
+					t.Errorf("diff %d path = %s, want %s", i, d.Path, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+
+	if ok, _ := CheckSubset(map[string]interface{}{"0": "a", "1": "b"}, []interface{}{"a", "b"}); ok {
+		t.Error("an object should not match an array without ArrayAsObject")
+	}
+}
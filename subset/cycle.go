@@ -0,0 +1,67 @@
+package subset
+
+import (
+	"reflect"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// cycleCheckDepth is the depth from which containers are tracked to catch
+// cycles. Decoded JSON has none, so shallower values pay nothing; a cyclic
+// value recurses past this depth and repeats there.
+const cycleCheckDepth = 64
+
+// containerID identifies a map or slice by the memory it refers to
+type containerID struct {
+	ptr uintptr
+	len int
+}
+
+// visitFrame is one container on the way from the root to the value being
+// compared, linked to the one enclosing it
+type visitFrame struct {
+	id     containerID
+	path   spec.NormalizedPath
+	parent *visitFrame
+}
+
+// containerIdentity returns the identity of a non-empty map or slice;
+// empty ones cannot contain anything, let alone themselves
+func containerIdentity(v interface{}) (containerID, bool) {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		if len(c) > 0 {
+			return containerID{ptr: reflect.ValueOf(c).Pointer(), len: -1}, true
+		}
+	case []interface{}:
+		if len(c) > 0 {
+			return containerID{ptr: reflect.ValueOf(c).Pointer(), len: len(c)}, true
+		}
+	default:
+		// Other map types are copied by toObject, so the original is tracked.
+		if rv := reflect.ValueOf(v); rv.Kind() == reflect.Map && rv.Len() > 0 {
+			return containerID{ptr: rv.Pointer(), len: -1}, true
+		}
+	}
+	return containerID{}, false
+}
+
+// enterContainer records v as being visited at path, once path is deep
+// enough for cycles to matter. If v encloses itself, it returns the path
+// where v was first visited and false.
+func enterContainer(v interface{}, path spec.NormalizedPath, opts *Options) (spec.NormalizedPath, bool) {
+	if len(path) < cycleCheckDepth {
+		return nil, true
+	}
+	id, ok := containerIdentity(v)
+	if !ok {
+		return nil, true
+	}
+	for f := opts.visiting; f != nil; f = f.parent {
+		if f.id == id {
+			return f.path, false
+		}
+	}
+	opts.visiting = &visitFrame{id: id, path: copyPath(path), parent: opts.visiting}
+	return nil, true
+}
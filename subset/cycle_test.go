@@ -0,0 +1,63 @@
+package subset
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCyclicValues(t *testing.T) {
+	cyclicMap := map[string]interface{}{"name": "loop"}
+	cyclicMap["self"] = cyclicMap
+
+	cyclicSlice := []interface{}{"x", nil}
+	cyclicSlice[1] = cyclicSlice
+
+	// Two maps that point at each other form a longer cycle.
+	a := map[string]interface{}{"kind": "a"}
+	b := map[string]interface{}{"kind": "b", "next": a}
+	a["next"] = b
+
+	// In set mode the cycle only makes the element search fail, so the
+	// diff is an element that was not found.
+	tests := []struct {
+		name      string
+		value     interface{}
+		opts      Options
+		wantCycle bool
+	}{
+		{"map", cyclicMap, Options{}, true},
+		{"slice", cyclicSlice, Options{ArrayOrder: ArrayOrdered}, true},
+		{"slice in set mode", cyclicSlice, Options{}, false},
+		{"two maps", a, Options{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, diffs := CheckSubsetWithOptions(tt.value, tt.value, tt.opts)
+			if ok || len(diffs) == 0 {
+				t.Fatalf("CheckSubsetWithOptions() = %v with %d diffs, want a cycle diff", ok, len(diffs))
+			}
+			if tt.wantCycle && !strings.HasPrefix(diffs[0].Message, "cyclic structure: the subset value contains itself") {
+				t.Errorf("message = %q, want a cyclic structure message", diffs[0].Message)
+			}
+		})
+	}
+
+	// A cycle in the superset only matters where the superset is walked.
+	ok, diffs := CheckSubsetWithOptions(map[string]interface{}{"name": "loop"}, cyclicMap, Options{RequiredKeys: []string{"name"}})
+	if ok || len(diffs) != 1 || !strings.HasPrefix(diffs[0].Message, "cyclic structure: the superset value contains itself") {
+		t.Errorf("required keys on a cyclic superset = %v, %+v, want one cycle diff", ok, diffs)
+	}
+}
+
+func TestDeepAcyclicValue(t *testing.T) {
+	// The same leaf map shared at many depths is not a cycle.
+	leaf := map[string]interface{}{"v": float64(1)}
+	var doc interface{} = leaf
+	for i := 0; i < 2*cycleCheckDepth; i++ {
+		doc = map[string]interface{}{"child": doc, "leaf": leaf}
+	}
+	if ok, diffs := CheckSubset(doc, doc); !ok {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+}
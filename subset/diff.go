@@ -0,0 +1,86 @@
+package subset
+
+import "github.com/theory/jsonpath/spec"
+
+// DiffType represents the type of difference
+type DiffType int
+
+const (
+	DiffMissingKey DiffType = iota
+	DiffValueMismatch
+	DiffTypeMismatch
+	DiffElementNotFound
+	DiffArrayLengthMismatch
+	DiffExtraKey
+)
+
+// Diff represents a single difference
+type Diff struct {
+	Path          spec.NormalizedPath
+	Type          DiffType
+	SubsetValue   interface{}
+	SupersetValue interface{}
+	Message       string
+	// SupersetLine is the line in the superset source the diff refers to,
+	// or 0 if unknown. See AddSupersetLines.
+	SupersetLine int
+	// Candidate is the superset element closest to a subset element that
+	// was not found in set mode, or nil if no object partially matches
+	Candidate *Candidate
+
+	// emitted marks a diff already passed to a WalkDiffs callback
+	emitted bool
+}
+
+// Candidate is a superset array element that partially matches a subset
+// element, and how the two differ
+type Candidate struct {
+	// Index is the position of the element in the superset array
+	Index int
+	// Value is the superset element
+	Value interface{}
+	// Diffs are the differences between the subset element and Value,
+	// with paths into the subset
+	Diffs []Diff
+}
+
+// AddSupersetLines sets SupersetLine on each diff from lines, which maps
+// normalized superset paths (relative to base) to source line numbers.
+// Missing keys and elements point at their parent; other diffs at the
+// nearest location that exists in the superset.
+func AddSupersetLines(diffs []Diff, lines map[string]int, base spec.NormalizedPath) {
+	for i := range diffs {
+		path := diffs[i].Path
+		if (diffs[i].Type == DiffMissingKey || diffs[i].Type == DiffElementNotFound) && len(path) > 0 {
+			path = path[:len(path)-1]
+		}
+		for n := len(path); n >= 0; n-- {
+			full := append(base[:len(base):len(base)], path[:n]...)
+			if line, ok := lines[full.String()]; ok {
+				diffs[i].SupersetLine = line
+				break
+			}
+		}
+	}
+}
+
+// String returns the name of a DiffType used in structured output, such
+// as "missing_key"
+func (t DiffType) String() string {
+	switch t {
+	case DiffMissingKey:
+		return "missing_key"
+	case DiffValueMismatch:
+		return "value_mismatch"
+	case DiffTypeMismatch:
+		return "type_mismatch"
+	case DiffElementNotFound:
+		return "element_not_found"
+	case DiffArrayLengthMismatch:
+		return "array_length_mismatch"
+	case DiffExtraKey:
+		return "extra_key"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,23 @@
+package subset
+
+import "testing"
+
+func TestDiffTypeString(t *testing.T) {
+	tests := []struct {
+		diffType DiffType
+		want     string
+	}{
+		{DiffMissingKey, "missing_key"},
+		{DiffValueMismatch, "value_mismatch"},
+		{DiffTypeMismatch, "type_mismatch"},
+		{DiffElementNotFound, "element_not_found"},
+		{DiffArrayLengthMismatch, "array_length_mismatch"},
+		{DiffExtraKey, "extra_key"},
+		{DiffType(-1), "unknown"},
+	}
+	for _, tt := range tests {
+		if got := tt.diffType.String(); got != tt.want {
+			t.Errorf("DiffType(%d).String() = %q, want %q", int(tt.diffType), got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package subset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// checkEmbedded compares strings at Options.ParseEmbedded locations by the
+// JSON they contain, so serialized payloads match regardless of key order
+// or spacing. handled is false for other locations, and for subset strings
+// that are not JSON, which are then compared as plain strings.
+func checkEmbedded(subset, superset interface{}, path spec.NormalizedPath, opts Options) (ok bool, diffs []Diff, handled bool) {
+	subsetStr, isString := subset.(string)
+	if !isString || !opts.embedded.contains(path) {
+		return false, nil, false
+	}
+	subsetDoc, err := decodeEmbedded(subsetStr)
+	if err != nil {
+		return false, nil, false
+	}
+
+	supersetStr, isString := superset.(string)
+	if !isString {
+		return false, []Diff{{Path: copyPath(path), Type: DiffTypeMismatch, SubsetValue: subset, SupersetValue: superset}}, true
+	}
+	supersetDoc, err := decodeEmbedded(supersetStr)
+	if err != nil {
+		return false, []Diff{{
+			Path:          copyPath(path),
+			Type:          DiffValueMismatch,
+			SubsetValue:   subset,
+			SupersetValue: superset,
+			Message:       fmt.Sprintf("superset value is not embedded JSON: %v", err),
+		}}, true
+	}
+
+	// The diffs are streamed once they carry their message.
+	opts.stream = nil
+	ok, diffs = checkSubsetPath(subsetDoc, supersetDoc, path, opts)
+	// The embedded values are not part of the rendered subset, so each
+	// diff carries its own explanation.
+	for i := range diffs {
+		if diffs[i].Message == "" {
+			diffs[i].Message = describeDiff(diffs[i])
+		}
+	}
+	return ok, diffs, true
+}
+
+// decodeEmbedded decodes a JSON document held in a string
+func decodeEmbedded(s string) (interface{}, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("invalid data after top-level value")
+	}
+	return doc, nil
+}
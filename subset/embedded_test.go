@@ -0,0 +1,66 @@
+package subset
+
+import "testing"
+
+func TestParseEmbedded(t *testing.T) {
+	subset := map[string]interface{}{
+		"type":    "order",
+		"payload": `{"id": 7, "status": "paid", "items": [{"sku": "a"}]}`,
+	}
+
+	tests := []struct {
+		name      string
+		patterns  []string
+		payload   interface{}
+		wantOK    bool
+		wantPaths []string
+	}{
+		{"key order differs", []string{"payload"}, `{"items":[{"qty":1,"sku":"a"}],"status":"paid","id":7}`, true, nil},
+		{"jsonpath", []string{"$.payload"}, `{"status": "paid", "id": 7, "items": [{"sku": "a"}]}`, true, nil},
+		{"compared as strings without the option", nil, `{"status": "paid", "id": 7, "items": [{"sku": "a"}]}`, false, []string{"$['payload']"}},
+		{"embedded difference", []string{"payload"}, `{"id": 8, "status": "paid", "items": [{"sku": "a"}]}`, false, []string{"$['payload']['id']"}},
+		{"superset not JSON", []string{"payload"}, `not json`, false, []string{"$['payload']"}},
+		{"superset not a string", []string{"payload"}, map[string]interface{}{"id": float64(7)}, false, []string{"$['payload']"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts Options
+			for _, s := range tt.patterns {
+				p, err := ParsePathPattern(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts.ParseEmbedded = append(opts.ParseEmbedded, p)
+			}
+			superset := map[string]interface{}{"type": "order", "payload": tt.payload}
+
+			ok, diffs := CheckSubsetWithOptions(subset, superset, opts)
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if len(diffs) != len(tt.wantPaths) {
+				t.Fatalf("got %d diffs, want %d: %+v", len(diffs), len(tt.wantPaths), diffs)
+			}
+			for i, d := range diffs {
+				if d.Path.String() != tt.wantPaths[i] {
+					t.Errorf("diff %d path = %s, want %s", i, d.Path, tt.wantPaths[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseEmbeddedPlainSubsetString(t *testing.T) {
+	p, _ := ParsePathPattern("note")
+	opts := Options{ParseEmbedded: []PathPattern{p}}
+
+	// A subset string that is not JSON is compared as it is.
+	subset := map[string]interface{}{"note": "hello"}
+	if ok, diffs := CheckSubsetWithOptions(subset, map[string]interface{}{"note": "hello"}, opts); !ok {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+	if ok, _ := CheckSubsetWithOptions(subset, map[string]interface{}{"note": "bye"}, opts); ok {
+		t.Error("different plain strings should not match")
+	}
+}
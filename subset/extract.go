@@ -0,0 +1,118 @@
+package subset
+
+import (
+	"sort"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// Extract returns the part of superset that the subset describes: the
+// superset values at the subset's keys and array elements, with everything
+// else pruned. The result is a subset of superset, and the subset matches
+// it whenever it matches superset, so it can be saved as a fixture.
+// Missing keys are left out, as are elements without a counterpart,
+// ignored locations and null placeholders with IgnoreNullValues. Array
+// elements are paired as the comparison would pair them, falling back to
+// the closest object in set mode.
+func Extract(subset, superset interface{}, opts Options) interface{} {
+	opts = prepareOptions(subset, opts)
+	opts.matches = nil
+	opts.Stats = nil
+	return extractPath(subset, superset, spec.NormalizedPath{}, opts)
+}
+
+func extractPath(subset, superset interface{}, path spec.NormalizedPath, opts Options) interface{} {
+	if opts.LimitDepth && len(path) > opts.MaxDepth {
+		return superset
+	}
+	if len(opts.rules) > 0 {
+		opts = applyRules(path, opts)
+	}
+
+	switch sub := subset.(type) {
+	case map[string]interface{}:
+		if sup, ok := superset.(map[string]interface{}); ok {
+			return extractObject(sub, sup, path, opts)
+		}
+	case []interface{}:
+		if sup, ok := superset.([]interface{}); ok {
+			return extractArray(sub, sup, path, opts)
+		}
+	}
+	return superset
+}
+
+func extractObject(subset, superset map[string]interface{}, path spec.NormalizedPath, opts Options) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, subsetValue := range subset {
+		childPath := append(copyPath(path), spec.Name(key))
+		if opts.ignored.contains(childPath) || (subsetValue == nil && opts.IgnoreNullValues) {
+			continue
+		}
+
+		if opts.EnableWildcard && key == wildcardKey {
+			for supersetKey, supersetValue := range superset {
+				if tryMatch(subsetValue, supersetValue, childPath, opts) {
+					result[supersetKey] = extractPath(subsetValue, supersetValue, childPath, opts)
+				}
+			}
+			continue
+		}
+
+		wantKey, _ := mappedKey(childPath, opts)
+		supersetKey, exists := lookupKey(superset, wantKey, opts)
+		if !exists {
+			continue
+		}
+		result[supersetKey] = extractPath(subsetValue, superset[supersetKey], childPath, opts)
+	}
+	return result
+}
+
+func extractArray(subset, superset []interface{}, path spec.NormalizedPath, opts Options) []interface{} {
+	result := make([]interface{}, 0, len(subset))
+	used := make(map[int]bool)
+	for i, subsetElem := range subset {
+		childPath := append(copyPath(path), spec.Index(i))
+		if opts.ignored.contains(childPath) {
+			continue
+		}
+
+		j := counterpart(subsetElem, i, superset, used, childPath, opts)
+		if j < 0 {
+			continue
+		}
+		used[j] = true
+		result = append(result, extractPath(subsetElem, superset[j], childPath, opts))
+	}
+	return result
+}
+
+// counterpart returns the index of the superset element a subset element
+// is compared with, or -1. In set and multiset mode an unused match is
+// preferred, so duplicates in the subset pick distinct elements.
+func counterpart(subsetElem interface{}, i int, superset []interface{}, used map[int]bool, path spec.NormalizedPath, opts Options) int {
+	if opts.ArrayKey != "" {
+		if id, keyed := elementKey(subsetElem, opts); keyed {
+			return findKeyedElement(id, superset, append(copyPath(path), spec.Name(opts.ArrayKey)), opts)
+		}
+	} else if opts.ArrayOrder == ArrayOrdered {
+		if i < len(superset) {
+			return i
+		}
+		return -1
+	}
+
+	var matches []int
+	for j, supersetElem := range superset {
+		if tryMatch(subsetElem, supersetElem, path, opts) {
+			matches = append(matches, j)
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool { return !used[matches[a]] && used[matches[b]] })
+	if len(matches) > 0 {
+		return matches[0]
+	}
+	j, _ := closestIndex(subsetElem, superset, path, opts)
+	return j
+}
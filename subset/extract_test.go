@@ -0,0 +1,93 @@
+package subset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	superset := map[string]interface{}{
+		"name": "alice",
+		"age":  float64(30),
+		"address": map[string]interface{}{
+			"city": "Tokyo",
+			"zip":  "100-0001",
+		},
+		"tags": []interface{}{"admin", "dev", "ops"},
+		"users": []interface{}{
+			map[string]interface{}{"id": float64(1), "name": "alice", "role": "admin"},
+			map[string]interface{}{"id": float64(2), "name": "bob", "role": "user"},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		subset interface{}
+		opts   Options
+		want   interface{}
+	}{
+		{
+			name:   "keeps only subset keys",
+			subset: map[string]interface{}{"name": "bob", "address": map[string]interface{}{"city": "Osaka"}},
+			want:   map[string]interface{}{"name": "alice", "address": map[string]interface{}{"city": "Tokyo"}},
+		},
+		{
+			name:   "missing keys are left out",
+			subset: map[string]interface{}{"name": "alice", "email": "a@example.com"},
+			want:   map[string]interface{}{"name": "alice"},
+		},
+		{
+			name:   "type mismatch takes the superset value",
+			subset: map[string]interface{}{"address": "Tokyo"},
+			want:   map[string]interface{}{"address": superset["address"]},
+		},
+		{
+			name:   "set mode picks matching elements",
+			subset: map[string]interface{}{"tags": []interface{}{"ops", "admin"}},
+			want:   map[string]interface{}{"tags": []interface{}{"ops", "admin"}},
+		},
+		{
+			name: "set mode falls back to the closest object",
+			subset: map[string]interface{}{"users": []interface{}{
+				map[string]interface{}{"id": float64(2), "name": "bob", "role": "admin"},
+			}},
+			want: map[string]interface{}{"users": []interface{}{
+				map[string]interface{}{"id": float64(2), "name": "bob", "role": "user"},
+			}},
+		},
+		{
+			name:   "ordered mode pairs by index",
+			subset: map[string]interface{}{"tags": []interface{}{"x", "y", "z", "w"}},
+			opts:   Options{ArrayOrder: ArrayOrdered},
+			want:   map[string]interface{}{"tags": []interface{}{"admin", "dev", "ops"}},
+		},
+		{
+			name: "keyed mode pairs by key",
+			subset: map[string]interface{}{"users": []interface{}{
+				map[string]interface{}{"id": float64(2), "role": "admin"},
+			}},
+			opts: Options{ArrayKey: "id"},
+			want: map[string]interface{}{"users": []interface{}{
+				map[string]interface{}{"id": float64(2), "role": "user"},
+			}},
+		},
+		{
+			name:   "ignored keys are left out",
+			subset: map[string]interface{}{"name": "alice", "age": float64(1)},
+			opts:   Options{Ignore: mustPatterns(t, "$.age")},
+			want:   map[string]interface{}{"name": "alice"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Extract(tt.subset, superset, tt.opts)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Extract() = %v, want %v", got, tt.want)
+			}
+			if ok, diffs := CheckSubsetWithOptions(got, superset, tt.opts); !ok {
+				t.Errorf("Extract() result is not a subset of the superset: %v", diffs)
+			}
+		})
+	}
+}
@@ -0,0 +1,697 @@
+package subset
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// Line represents a single line of output with its path
+type Line struct {
+	Content string
+	Path    spec.NormalizedPath
+}
+
+// FormatOptions controls how the diff tree is rendered
+type FormatOptions struct {
+	// Color highlights diff lines with ANSI escape codes
+	Color bool
+	// ValueWidth truncates rendered values to this many characters; 0 means unlimited
+	ValueWidth int
+	// KeyOrder maps the normalized path of an object to its keys in the
+	// order they should be shown; other objects have their keys sorted
+	KeyOrder map[string][]string
+	// Matched lists subset leaves annotated with "# ok", as returned by CheckSubsetExplain
+	Matched []spec.NormalizedPath
+	// DiffMarker prefixes lines with a difference instead of "-"
+	DiffMarker string
+	// OKMarker prefixes unchanged lines instead of " "
+	OKMarker string
+	// LimitContext shows only the lines with a difference and Context
+	// lines around each, like diff -U, replacing each run of hidden lines
+	// with a "@@ ... @@" line
+	LimitContext bool
+	Context      int
+	// MaxDiffs, if positive, renders only the first MaxDiffs differences
+	// and ends with a line counting the rest. Extra keys are not counted.
+	MaxDiffs int
+	// ValuesOnly replaces the subset tree with one "path: subset != superset"
+	// line per difference, leaving out braces, brackets and matching values
+	ValuesOnly bool
+	// PathStyle selects how paths are written in the output
+	PathStyle PathStyle
+}
+
+// extraMarker prefixes extra superset keys shown with ShowExtra
+const extraMarker = "+"
+
+// markers returns the diff and unchanged line prefixes, applying the defaults
+func (opts FormatOptions) markers() (diff, ok string) {
+	diff, ok = opts.DiffMarker, opts.OKMarker
+	if diff == "" {
+		diff = "-"
+	}
+	if ok == "" {
+		ok = " "
+	}
+	return diff, ok
+}
+
+// DefaultValueWidth is the truncation width used by the command line tool
+const DefaultValueWidth = 50
+
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorReset = "\x1b[0m"
+)
+
+// FormatDiffOutput formats the subset JSON with diff markers
+func FormatDiffOutput(subset interface{}, diffs []Diff) string {
+	return FormatDiffOutputWithOptions(subset, diffs, FormatOptions{})
+}
+
+// FormatDiffOutputWithOptions formats the subset JSON with diff markers
+func FormatDiffOutputWithOptions(subset interface{}, diffs []Diff, opts FormatOptions) string {
+	diffs, hidden := capDiffs(diffs, opts.MaxDiffs)
+	var output string
+	if opts.ValuesOnly {
+		output = formatValuesOnly(diffs, opts)
+	} else {
+		output = formatTree(subset, diffs, opts)
+	}
+	switch {
+	case hidden == 1:
+		output += "... and 1 more difference\n"
+	case hidden > 1:
+		output += fmt.Sprintf("... and %d more differences\n", hidden)
+	}
+	return output
+}
+
+// formatTree renders the subset with diff markers and notes
+func formatTree(subset interface{}, diffs []Diff, opts FormatOptions) string {
+	marks := lineMarks{
+		diffPaths:  make(map[string]bool),
+		extraPaths: make(map[string]bool),
+		matched:    make(map[string]bool),
+		notes:      make(map[string]string),
+	}
+	for _, d := range diffs {
+		if d.Type == DiffExtraKey {
+			marks.extraPaths[d.Path.String()] = true
+			subset = insertValue(subset, d.Path, d.SupersetValue)
+			continue
+		}
+		marks.diffPaths[d.Path.String()] = true
+		if d.Type == DiffValueMismatch || d.Type == DiffTypeMismatch {
+			note := "(superset: " + formatValue(d.SupersetValue, opts.ValueWidth)
+			if d.SupersetLine > 0 {
+				note += fmt.Sprintf(", line %d", d.SupersetLine)
+			}
+			marks.notes[d.Path.String()] = note + ")"
+		} else if d.SupersetLine > 0 {
+			marks.notes[d.Path.String()] = fmt.Sprintf("(superset line %d)", d.SupersetLine)
+		}
+		addCandidateNotes(marks, d, opts)
+	}
+	for _, p := range opts.Matched {
+		marks.matched[p.String()] = true
+	}
+
+	lines := generateLines(subset, spec.NormalizedPath{}, 0, opts.KeyOrder)
+	return formatOutput(lines, marks, opts) + formatUnrendered(lines, diffs, opts)
+}
+
+// formatValuesOnly renders each difference as "path: subset != superset",
+// with "(missing)" for the side a key is absent from and "(not found)" for
+// an array element that has no match
+func formatValuesOnly(diffs []Diff, opts FormatOptions) string {
+	var sb strings.Builder
+	for _, d := range diffs {
+		subsetSide := formatValue(d.SubsetValue, opts.ValueWidth)
+		supersetSide := formatValue(d.SupersetValue, opts.ValueWidth)
+		color := colorRed
+		switch d.Type {
+		case DiffMissingKey:
+			supersetSide = "(missing)"
+		case DiffElementNotFound:
+			supersetSide = "(not found)"
+		case DiffExtraKey:
+			subsetSide = "(missing)"
+			color = colorGreen
+		}
+		if opts.Color {
+			sb.WriteString(color)
+		}
+		fmt.Fprintf(&sb, "%s: %s != %s", FormatPath(d.Path, opts.PathStyle), subsetSide, supersetSide)
+		if opts.Color {
+			sb.WriteString(colorReset)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// addCandidateNotes annotates an element that was not found with how its
+// closest superset match differs, down to the leaves, so a near miss in a
+// mixed array can be read off the text output
+func addCandidateNotes(marks lineMarks, d Diff, opts FormatOptions) {
+	if d.Candidate == nil {
+		return
+	}
+	if _, ok := marks.notes[d.Path.String()]; !ok {
+		marks.notes[d.Path.String()] = fmt.Sprintf("(closest match is superset index %d)", d.Candidate.Index)
+	}
+	for _, cd := range d.Candidate.Diffs {
+		key := cd.Path.String()
+		if _, ok := marks.notes[key]; ok {
+			continue
+		}
+		switch cd.Type {
+		case DiffValueMismatch, DiffTypeMismatch:
+			marks.notes[key] = "(closest match: " + formatValue(cd.SupersetValue, opts.ValueWidth) + ")"
+		case DiffMissingKey:
+			marks.notes[key] = "(closest match: missing)"
+		case DiffElementNotFound:
+			addCandidateNotes(marks, cd, opts)
+		}
+	}
+}
+
+// capDiffs keeps the first limit differences, and every extra key,
+// returning how many differences were dropped. A limit of 0 keeps everything.
+func capDiffs(diffs []Diff, limit int) ([]Diff, int) {
+	if limit <= 0 {
+		return diffs, 0
+	}
+	kept := make([]Diff, 0, len(diffs))
+	shown, hidden := 0, 0
+	for _, d := range diffs {
+		if d.Type != DiffExtraKey {
+			if shown == limit {
+				hidden++
+				continue
+			}
+			shown++
+		}
+		kept = append(kept, d)
+	}
+	return kept, hidden
+}
+
+// lineMarks holds the per-path annotations applied by formatOutput
+type lineMarks struct {
+	diffPaths  map[string]bool
+	extraPaths map[string]bool
+	matched    map[string]bool
+	notes      map[string]string
+}
+
+// insertValue returns a copy of doc with value set at path, so extra
+// superset keys can be rendered in place. The subset itself is not modified.
+func insertValue(doc interface{}, path spec.NormalizedPath, value interface{}) interface{} {
+	if len(path) == 0 {
+		return value
+	}
+
+	switch sel := path[0].(type) {
+	case spec.Name:
+		m, ok := doc.(map[string]interface{})
+		if !ok {
+			return doc
+		}
+		copied := make(map[string]interface{}, len(m)+1)
+		for k, v := range m {
+			copied[k] = v
+		}
+		copied[string(sel)] = insertValue(m[string(sel)], path[1:], value)
+		return copied
+
+	case spec.Index:
+		arr, ok := doc.([]interface{})
+		if !ok || int(sel) >= len(arr) {
+			return doc
+		}
+		copied := append([]interface{}{}, arr...)
+		copied[sel] = insertValue(arr[sel], path[1:], value)
+		return copied
+	}
+	return doc
+}
+
+// formatValue renders a value as compact JSON, truncated to width characters
+func formatValue(value interface{}, width int) string {
+	var s string
+	if data, err := json.Marshal(value); err == nil {
+		s = string(data)
+	} else {
+		s = fmt.Sprintf("%v", value)
+	}
+
+	runes := []rune(s)
+	if width > 0 && len(runes) > width {
+		return string(runes[:width]) + "..."
+	}
+	return s
+}
+
+// FormatDiffSummary returns a line like "3 differences found (2 missing keys, 1 value mismatch)"
+func FormatDiffSummary(diffs []Diff) string {
+	counts := make(map[DiffType]int)
+	var types []DiffType
+	for _, d := range diffs {
+		if counts[d.Type] == 0 {
+			types = append(types, d.Type)
+		}
+		counts[d.Type]++
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[t], diffTypeLabel(t, counts[t])))
+	}
+
+	noun := "differences"
+	if len(diffs) == 1 {
+		noun = "difference"
+	}
+	if len(parts) == 0 {
+		return fmt.Sprintf("%d %s found", len(diffs), noun)
+	}
+	return fmt.Sprintf("%d %s found (%s)", len(diffs), noun, strings.Join(parts, ", "))
+}
+
+// diffTypeLabel returns a human-readable name for n diffs of type t
+func diffTypeLabel(t DiffType, n int) string {
+	singular, plural := "unknown difference", "unknown differences"
+	switch t {
+	case DiffMissingKey:
+		singular, plural = "missing key", "missing keys"
+	case DiffValueMismatch:
+		singular, plural = "value mismatch", "value mismatches"
+	case DiffTypeMismatch:
+		singular, plural = "type mismatch", "type mismatches"
+	case DiffElementNotFound:
+		singular, plural = "element not found", "elements not found"
+	case DiffArrayLengthMismatch:
+		singular, plural = "array length mismatch", "array length mismatches"
+	case DiffExtraKey:
+		singular, plural = "extra key", "extra keys"
+	}
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
+
+// jsonDiff is the serialized form of a Diff
+type jsonDiff struct {
+	Path          string         `json:"path"`
+	Type          string         `json:"type"`
+	SubsetValue   interface{}    `json:"subset"`
+	SupersetValue interface{}    `json:"superset"`
+	Message       string         `json:"message,omitempty"`
+	SupersetLine  int            `json:"superset_line,omitempty"`
+	Candidate     *jsonCandidate `json:"candidate,omitempty"`
+}
+
+// jsonCandidate is the serialized form of a Candidate
+type jsonCandidate struct {
+	Index int         `json:"index"`
+	Value interface{} `json:"superset"`
+	Diffs []jsonDiff  `json:"diffs"`
+}
+
+// FormatDiffJSON serializes diffs as a JSON array
+func FormatDiffJSON(diffs []Diff) (string, error) {
+	return FormatDiffJSONWithOptions(diffs, FormatOptions{})
+}
+
+// FormatDiffJSONWithOptions serializes diffs as a JSON array with paths
+// written in opts.PathStyle. Other options do not apply.
+func FormatDiffJSONWithOptions(diffs []Diff, opts FormatOptions) (string, error) {
+	data, err := json.MarshalIndent(toJSONDiffs(diffs, opts.PathStyle), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func toJSONDiffs(diffs []Diff, style PathStyle) []jsonDiff {
+	entries := make([]jsonDiff, 0, len(diffs))
+	for _, d := range diffs {
+		entry := jsonDiff{
+			Path:          FormatPath(d.Path, style),
+			Type:          d.Type.String(),
+			SubsetValue:   d.SubsetValue,
+			SupersetValue: d.SupersetValue,
+			Message:       d.Message,
+			SupersetLine:  d.SupersetLine,
+		}
+		if c := d.Candidate; c != nil {
+			entry.Candidate = &jsonCandidate{Index: c.Index, Value: c.Value, Diffs: toJSONDiffs(c.Diffs, style)}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// patchOperation is a single RFC 6902 JSON Patch operation
+type patchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value"`
+}
+
+// FormatDiffJSONPatch converts diffs into an RFC 6902 JSON Patch that,
+// applied to the superset, makes it contain the subset.
+func FormatDiffJSONPatch(diffs []Diff) (string, error) {
+	ops := make([]patchOperation, 0, len(diffs))
+	for _, d := range diffs {
+		switch d.Type {
+		case DiffMissingKey:
+			ops = append(ops, patchOperation{Op: "add", Path: d.Path.Pointer(), Value: d.SubsetValue})
+		case DiffValueMismatch, DiffTypeMismatch:
+			ops = append(ops, patchOperation{Op: "replace", Path: d.Path.Pointer(), Value: d.SubsetValue})
+		case DiffElementNotFound:
+			// The subset index says nothing about the superset position, so append.
+			ops = append(ops, patchOperation{Op: "add", Path: d.Path[:len(d.Path)-1].Pointer() + "/-", Value: d.SubsetValue})
+		default:
+			// Length mismatches cannot be fixed by adding or replacing
+			// values, and extra keys need no change.
+		}
+	}
+
+	data, err := json.MarshalIndent(ops, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// objectKeys returns the keys of obj in the order recorded for path, or
+// sorted if none was. Keys missing from the recorded order, such as
+// inserted extra keys, follow in sorted order.
+func objectKeys(obj map[string]interface{}, path spec.NormalizedPath, order map[string][]string) []string {
+	keys := make([]string, 0, len(obj))
+	seen := make(map[string]bool, len(obj))
+	for _, k := range order[path.String()] {
+		if _, ok := obj[k]; ok && !seen[k] {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+	ordered := len(keys)
+
+	for k := range obj {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[ordered:])
+	return keys
+}
+
+// generateLines generates lines from JSON value with path information
+func generateLines(value interface{}, path spec.NormalizedPath, indent int, order map[string][]string) []Line {
+	indentStr := strings.Repeat("  ", indent)
+	if obj, ok := toObject(value); ok {
+		value = obj
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return generateObjectLines(v, path, indent, order)
+
+	case []interface{}:
+		return generateArrayLines(v, path, indent, order)
+
+	default:
+		return []Line{{Content: indentStr + formatPrimitive(value), Path: copyPath(path)}}
+	}
+}
+
+func generateObjectLines(obj map[string]interface{}, path spec.NormalizedPath, indent int, order map[string][]string) []Line {
+	indentStr := strings.Repeat("  ", indent)
+	var lines []Line
+
+	lines = append(lines, Line{Content: indentStr + "{", Path: copyPath(path)})
+
+	keys := objectKeys(obj, path, order)
+
+	for i, key := range keys {
+		childPath := append(copyPath(path), spec.Name(key))
+		childValue := obj[key]
+		comma := ","
+		if i == len(keys)-1 {
+			comma = ""
+		}
+
+		childLines := generateKeyValueLines(key, childValue, childPath, indent+1, comma, order)
+		lines = append(lines, childLines...)
+	}
+
+	lines = append(lines, Line{Content: indentStr + "}", Path: copyPath(path)})
+	return lines
+}
+
+func generateArrayLines(arr []interface{}, path spec.NormalizedPath, indent int, order map[string][]string) []Line {
+	indentStr := strings.Repeat("  ", indent)
+	var lines []Line
+
+	lines = append(lines, Line{Content: indentStr + "[", Path: copyPath(path)})
+
+	for i, elem := range arr {
+		childPath := append(copyPath(path), spec.Index(i))
+		comma := ","
+		if i == len(arr)-1 {
+			comma = ""
+		}
+
+		childLines := generateLines(elem, childPath, indent+1, order)
+		if len(childLines) > 0 {
+			lastIdx := len(childLines) - 1
+			childLines[lastIdx].Content += comma
+		}
+		lines = append(lines, childLines...)
+	}
+
+	lines = append(lines, Line{Content: indentStr + "]", Path: copyPath(path)})
+	return lines
+}
+
+func generateKeyValueLines(key string, value interface{}, path spec.NormalizedPath, indent int, comma string, order map[string][]string) []Line {
+	indentStr := strings.Repeat("  ", indent)
+	if obj, ok := toObject(value); ok {
+		value = obj
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		var lines []Line
+		lines = append(lines, Line{Content: indentStr + fmt.Sprintf("%q: {", key), Path: copyPath(path)})
+
+		keys := objectKeys(v, path, order)
+
+		for i, childKey := range keys {
+			childPath := append(copyPath(path), spec.Name(childKey))
+			childComma := ","
+			if i == len(keys)-1 {
+				childComma = ""
+			}
+			childLines := generateKeyValueLines(childKey, v[childKey], childPath, indent+1, childComma, order)
+			lines = append(lines, childLines...)
+		}
+
+		lines = append(lines, Line{Content: indentStr + "}" + comma, Path: copyPath(path)})
+		return lines
+
+	case []interface{}:
+		var lines []Line
+		lines = append(lines, Line{Content: indentStr + fmt.Sprintf("%q: [", key), Path: copyPath(path)})
+
+		for i, elem := range v {
+			childPath := append(copyPath(path), spec.Index(i))
+			childComma := ","
+			if i == len(v)-1 {
+				childComma = ""
+			}
+
+			childLines := generateLines(elem, childPath, indent+1, order)
+			if len(childLines) > 0 {
+				lastIdx := len(childLines) - 1
+				childLines[lastIdx].Content += childComma
+			}
+			lines = append(lines, childLines...)
+		}
+
+		lines = append(lines, Line{Content: indentStr + "]" + comma, Path: copyPath(path)})
+		return lines
+
+	default:
+		content := indentStr + fmt.Sprintf("%q: %s%s", key, formatPrimitive(value), comma)
+		return []Line{{Content: content, Path: copyPath(path)}}
+	}
+}
+
+func formatPrimitive(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case float64:
+		if v == float64(int64(v)) {
+			return fmt.Sprintf("%.0f", v)
+		}
+		return fmt.Sprintf("%v", v)
+	case bool:
+		return fmt.Sprintf("%v", v)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// formatUnrendered lists diffs whose path is not part of the rendered
+// subset, such as required keys missing deep in the superset, or extra
+// keys that could not be inserted into it
+func formatUnrendered(lines []Line, diffs []Diff, opts FormatOptions) string {
+	rendered := make(map[string]bool, len(lines))
+	for _, line := range lines {
+		rendered[line.Path.String()] = true
+	}
+
+	diffMarker, _ := opts.markers()
+	var sb strings.Builder
+	for _, d := range diffs {
+		if rendered[d.Path.String()] {
+			continue
+		}
+		marker, color := diffMarker, colorRed
+		if d.Type == DiffExtraKey {
+			marker, color = extraMarker, colorGreen
+		}
+		if opts.Color {
+			sb.WriteString(color)
+		}
+		sb.WriteString(marker)
+		sb.WriteString(" ")
+		sb.WriteString(FormatPath(d.Path, opts.PathStyle))
+		if d.Message != "" {
+			sb.WriteString(": ")
+			sb.WriteString(d.Message)
+		}
+		if opts.Color {
+			sb.WriteString(colorReset)
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// formatOutput formats lines with diff markers. A note is appended to the
+// first line of the value at its path.
+func formatOutput(lines []Line, marks lineMarks, opts FormatOptions) string {
+	diffMarker, okMarker := opts.markers()
+	rendered := make([]string, len(lines))
+	changed := make([]bool, len(lines))
+
+	for i, line := range lines {
+		var sb strings.Builder
+		if shouldMarkAsDiff(line.Path, marks.extraPaths) {
+			changed[i] = true
+			if opts.Color {
+				sb.WriteString(colorGreen)
+			}
+			sb.WriteString(extraMarker)
+			sb.WriteString(line.Content)
+			if opts.Color {
+				sb.WriteString(colorReset)
+			}
+			rendered[i] = sb.String()
+			continue
+		}
+
+		if !shouldMarkAsDiff(line.Path, marks.diffPaths) {
+			sb.WriteString(okMarker)
+			sb.WriteString(line.Content)
+			if marks.matched[line.Path.String()] {
+				sb.WriteString(" # ok")
+			}
+			rendered[i] = sb.String()
+			continue
+		}
+
+		changed[i] = true
+		if opts.Color {
+			sb.WriteString(colorRed)
+		}
+		sb.WriteString(diffMarker)
+		sb.WriteString(line.Content)
+		if note, ok := marks.notes[line.Path.String()]; ok {
+			sb.WriteString(" ")
+			sb.WriteString(note)
+			delete(marks.notes, line.Path.String())
+		}
+		if opts.Color {
+			sb.WriteString(colorReset)
+		}
+		rendered[i] = sb.String()
+	}
+
+	var sb strings.Builder
+	keep := contextLines(changed, opts)
+	for i := range rendered {
+		switch {
+		case keep[i]:
+			sb.WriteString(rendered[i])
+			sb.WriteString("\n")
+		case i == 0 || keep[i-1]:
+			sb.WriteString(contextGap + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// contextGap replaces a run of unchanged lines hidden by FormatOptions.Context
+const contextGap = "@@ ... @@"
+
+// contextLines reports which lines to show: all of them, or with
+// LimitContext the changed lines and Context lines on either side. When no
+// line changed, everything is shown.
+func contextLines(changed []bool, opts FormatOptions) []bool {
+	keep := make([]bool, len(changed))
+	anyChanged := false
+	for i, c := range changed {
+		if !c {
+			continue
+		}
+		anyChanged = true
+		for j := max(0, i-opts.Context); j <= min(len(changed)-1, i+opts.Context); j++ {
+			keep[j] = true
+		}
+	}
+	if !opts.LimitContext || !anyChanged {
+		for i := range keep {
+			keep[i] = true
+		}
+	}
+	return keep
+}
+
+// shouldMarkAsDiff checks if a line should be marked as diff: its own path
+// or one of its ancestors has a diff. Ancestors are compared segment by
+// segment, so a diff at $['a']['b'] never marks a sibling like $['a']['bc'].
+func shouldMarkAsDiff(path spec.NormalizedPath, diffPaths map[string]bool) bool {
+	for i := len(path); i >= 0; i-- {
+		if diffPaths[path[:i].String()] {
+			return true
+		}
+	}
+	return false
+}
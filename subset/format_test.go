@@ -0,0 +1,533 @@
+package subset
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+func TestDiffOutput(t *testing.T) {
+	subset := map[string]interface{}{
+		"user": map[string]interface{}{
+			"name":  "alice",
+			"email": "alice@example.com",
+		},
+	}
+	superset := map[string]interface{}{
+		"user": map[string]interface{}{
+			"name": "alice",
+		},
+	}
+
+	_, diffs := CheckSubset(subset, superset)
+	output := FormatDiffOutput(subset, diffs)
+
+	if !strings.Contains(output, "-") {
+		t.Error("diff output should contain '-' marker")
+	}
+	if !strings.Contains(output, "email") {
+		t.Error("diff output should contain missing key 'email'")
+	}
+}
+
+func TestFormatDiffJSON(t *testing.T) {
+	subset := map[string]interface{}{
+		"name": "alice",
+		"user": map[string]interface{}{"email": "alice@example.com"},
+	}
+	superset := map[string]interface{}{
+		"name": "bob",
+		"user": map[string]interface{}{},
+	}
+
+	_, diffs := CheckSubset(subset, superset)
+	output, err := FormatDiffJSON(diffs)
+	if err != nil {
+		t.Fatalf("FormatDiffJSON() error = %v", err)
+	}
+
+	var got []map[string]interface{}
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2", len(got))
+	}
+
+	want := []map[string]interface{}{
+		{"path": "$['name']", "type": "value_mismatch", "subset": "alice", "superset": "bob"},
+		{"path": "$['user']['email']", "type": "missing_key", "subset": "alice@example.com", "superset": nil},
+	}
+	for i, w := range want {
+		for k, v := range w {
+			if got[i][k] != v {
+				t.Errorf("entry %d: %s = %v, want %v", i, k, got[i][k], v)
+			}
+		}
+	}
+}
+
+func TestFormatDiffJSONCandidate(t *testing.T) {
+	subset := []interface{}{map[string]interface{}{"id": float64(2), "role": "admin"}}
+	superset := []interface{}{map[string]interface{}{"id": float64(2), "role": "user"}}
+
+	_, diffs := CheckSubset(subset, superset)
+	output, err := FormatDiffJSON(diffs)
+	if err != nil {
+		t.Fatalf("FormatDiffJSON() error = %v", err)
+	}
+
+	var got []struct {
+		Candidate struct {
+			Index int                      `json:"index"`
+			Diffs []map[string]interface{} `json:"diffs"`
+		} `json:"candidate"`
+	}
+	if err := json.Unmarshal([]byte(output), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+	if len(got) != 1 || len(got[0].Candidate.Diffs) != 1 {
+		t.Fatalf("output = %s, want one diff with one candidate diff", output)
+	}
+	if d := got[0].Candidate.Diffs[0]; d["path"] != "$[0]['role']" || d["superset"] != "user" {
+		t.Errorf("candidate diff = %v, want the role mismatch", d)
+	}
+}
+
+func TestFormatDiffJSONEmpty(t *testing.T) {
+	output, err := FormatDiffJSON(nil)
+	if err != nil {
+		t.Fatalf("FormatDiffJSON() error = %v", err)
+	}
+	if output != "[]" {
+		t.Errorf("FormatDiffJSON(nil) = %q, want %q", output, "[]")
+	}
+}
+
+func TestFormatDiffOutputColor(t *testing.T) {
+	subset := map[string]interface{}{"a": float64(1), "b": float64(2)}
+	superset := map[string]interface{}{"a": float64(1)}
+	_, diffs := CheckSubset(subset, superset)
+
+	plain := FormatDiffOutput(subset, diffs)
+	if got := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{}); got != plain {
+		t.Errorf("output without color differs from FormatDiffOutput:\n%s\nvs\n%s", got, plain)
+	}
+	if strings.Contains(plain, "\x1b[") {
+		t.Errorf("plain output should not contain escape codes: %q", plain)
+	}
+
+	colored := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{Color: true})
+	if !strings.Contains(colored, colorRed+`-  "b": 2`+colorReset) {
+		t.Errorf("diff line should be red, got %q", colored)
+	}
+	if !strings.Contains(colored, "\n   \"a\": 1,\n") {
+		t.Errorf("unchanged line should not be colored, got %q", colored)
+	}
+}
+
+func TestFormatDiffOutputMarkers(t *testing.T) {
+	subset := map[string]interface{}{"a": float64(1), "b": float64(2)}
+	superset := map[string]interface{}{"a": float64(1)}
+	_, diffs := CheckSubset(subset, superset)
+
+	got := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{DiffMarker: "!", OKMarker: "."})
+	want := ".{\n.  \"a\": 1,\n!  \"b\": 2\n.}\n"
+	if got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+
+	// Diffs listed below the tree, such as required keys, use the marker too.
+	_, diffs = CheckSubsetWithOptions(subset, map[string]interface{}{"a": float64(1), "b": float64(2), "c": map[string]interface{}{}}, Options{RequiredKeys: []string{"id"}})
+	got = FormatDiffOutputWithOptions(subset, diffs, FormatOptions{DiffMarker: "!", OKMarker: "."})
+	if !strings.Contains(got, "\n! $['c']['id']") || strings.Contains(got, "\n- ") {
+		t.Errorf("unrendered diffs should use the diff marker, got %q", got)
+	}
+}
+
+func TestFormatDiffOutputMaxDiffs(t *testing.T) {
+	subset := map[string]interface{}{"a": float64(1), "b": float64(2), "c": float64(3), "d": float64(4)}
+	_, diffs := CheckSubsetWithOptions(subset, map[string]interface{}{"e": float64(5)}, Options{ShowExtra: true})
+	if len(diffs) != 5 {
+		t.Fatalf("got %d diffs, want 5", len(diffs))
+	}
+
+	got := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{MaxDiffs: 2})
+	if strings.Count(got, "\n-") != 2 {
+		t.Errorf("want 2 rendered differences, got:\n%s", got)
+	}
+	if !strings.Contains(got, "\n+  \"e\": 5") {
+		t.Errorf("extra keys should not count toward the cap, got:\n%s", got)
+	}
+	if !strings.HasSuffix(got, "... and 2 more differences\n") {
+		t.Errorf("output should end with the hidden count, got:\n%s", got)
+	}
+
+	if got := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{MaxDiffs: 3}); !strings.HasSuffix(got, "... and 1 more difference\n") {
+		t.Errorf("output should end with the singular hidden count, got:\n%s", got)
+	}
+	if got := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{MaxDiffs: 4}); strings.Contains(got, "more difference") {
+		t.Errorf("nothing should be hidden, got:\n%s", got)
+	}
+}
+
+func TestFormatDiffOutputValuesOnly(t *testing.T) {
+	subset := map[string]interface{}{
+		"name": "alice",
+		"user": map[string]interface{}{"email": "alice@example.com", "age": float64(30)},
+		"tags": []interface{}{"admin"},
+	}
+	superset := map[string]interface{}{
+		"name": "bob",
+		"user": map[string]interface{}{"age": "thirty"},
+		"tags": []interface{}{"dev"},
+		"id":   float64(1),
+	}
+	_, diffs := CheckSubsetWithOptions(subset, superset, Options{ShowExtra: true})
+
+	want := `$['name']: "alice" != "bob"
+$['tags'][0]: "admin" != (not found)
+$['user']['age']: 30 != "thirty"
+$['user']['email']: "alice@example.com" != (missing)
+$['id']: (missing) != 1
+`
+	if got := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{ValuesOnly: true}); got != want {
+		t.Errorf("FormatDiffOutputWithOptions() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Extra keys are not counted toward MaxDiffs.
+	got := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{ValuesOnly: true, MaxDiffs: 1, ValueWidth: 6})
+	want = "$['name']: \"alice... != \"bob\"\n$['id']: (missing) != 1\n... and 3 more differences\n"
+	if got != want {
+		t.Errorf("with MaxDiffs and ValueWidth =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatDiffOutputContextGolden(t *testing.T) {
+	var sub, super interface{}
+	if err := json.Unmarshal([]byte(`{
+		"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6,
+		"g": {"h": 7, "i": 8, "j": 9},
+		"k": 10, "l": 11, "m": 12, "n": 13, "o": 14
+	}`), &sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{
+		"a": 0, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6,
+		"g": {"h": 7, "i": 0, "j": 9},
+		"k": 10, "l": 11, "m": 12, "n": 13
+	}`), &super); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diffs := CheckSubset(sub, super)
+	got := FormatDiffOutputWithOptions(sub, diffs, FormatOptions{LimitContext: true, Context: 1})
+
+	want, err := os.ReadFile(filepath.Join("testdata", "context.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("FormatDiffOutputWithOptions() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Without differences, nothing is hidden.
+	full := FormatDiffOutputWithOptions(sub, nil, FormatOptions{})
+	if got := FormatDiffOutputWithOptions(sub, nil, FormatOptions{LimitContext: true}); got != full {
+		t.Errorf("context output without differences =\n%s\nwant\n%s", got, full)
+	}
+}
+
+func TestFormatDiffSummary(t *testing.T) {
+	tests := []struct {
+		name  string
+		diffs []Diff
+		want  string
+	}{
+		{
+			name:  "single",
+			diffs: []Diff{{Type: DiffMissingKey}},
+			want:  "1 difference found (1 missing key)",
+		},
+		{
+			name: "grouped by type",
+			diffs: []Diff{
+				{Type: DiffValueMismatch},
+				{Type: DiffMissingKey},
+				{Type: DiffMissingKey},
+			},
+			want: "3 differences found (2 missing keys, 1 value mismatch)",
+		},
+		{
+			name: "all types",
+			diffs: []Diff{
+				{Type: DiffElementNotFound},
+				{Type: DiffElementNotFound},
+				{Type: DiffTypeMismatch},
+				{Type: DiffTypeMismatch},
+			},
+			want: "4 differences found (2 type mismatches, 2 elements not found)",
+		},
+		{
+			name: "none",
+			want: "0 differences found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDiffSummary(tt.diffs); got != tt.want {
+				t.Errorf("FormatDiffSummary() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDiffJSONPatch(t *testing.T) {
+	subset := map[string]interface{}{
+		"name": "alice",
+		"user": map[string]interface{}{"email": "alice@example.com", "age": float64(30)},
+		"tags": []interface{}{"admin", "dev"},
+	}
+	superset := map[string]interface{}{
+		"name": "bob",
+		"user": map[string]interface{}{"age": "thirty"},
+		"tags": []interface{}{"dev"},
+		"id":   float64(1),
+	}
+
+	_, diffs := CheckSubset(subset, superset)
+	output, err := FormatDiffJSONPatch(diffs)
+	if err != nil {
+		t.Fatalf("FormatDiffJSONPatch() error = %v", err)
+	}
+
+	var ops []patchOperation
+	if err := json.Unmarshal([]byte(output), &ops); err != nil {
+		t.Fatalf("output is not valid JSON: %v", err)
+	}
+
+	want := []struct{ op, path string }{
+		{"replace", "/name"},
+		{"add", "/tags/-"},
+		{"replace", "/user/age"},
+		{"add", "/user/email"},
+	}
+	if len(ops) != len(want) {
+		t.Fatalf("got %d operations, want %d: %s", len(ops), len(want), output)
+	}
+	for i, w := range want {
+		if ops[i].Op != w.op || ops[i].Path != w.path {
+			t.Errorf("operation %d = %s %s, want %s %s", i, ops[i].Op, ops[i].Path, w.op, w.path)
+		}
+	}
+
+	patched := applyPatch(t, superset, ops)
+	if ok, diffs := CheckSubset(subset, patched); !ok {
+		t.Errorf("patched superset should contain subset, diffs: %+v", diffs)
+	}
+}
+
+// applyPatch applies add and replace operations on object keys and array appends
+func applyPatch(t *testing.T, doc interface{}, ops []patchOperation) interface{} {
+	t.Helper()
+	for _, op := range ops {
+		tokens := strings.Split(op.Path, "/")[1:]
+		parentTokens, last := tokens[:len(tokens)-1], tokens[len(tokens)-1]
+
+		parent := doc
+		for _, tok := range parentTokens {
+			parent = parent.(map[string]interface{})[tok]
+		}
+
+		if last == "-" {
+			grandparent := doc
+			for _, tok := range parentTokens[:len(parentTokens)-1] {
+				grandparent = grandparent.(map[string]interface{})[tok]
+			}
+			key := parentTokens[len(parentTokens)-1]
+			m := grandparent.(map[string]interface{})
+			m[key] = append(m[key].([]interface{}), op.Value)
+			continue
+		}
+		parent.(map[string]interface{})[last] = op.Value
+	}
+	return doc
+}
+
+func TestFormatValueWidth(t *testing.T) {
+	long := strings.Repeat("x", 60)
+
+	tests := []struct {
+		name  string
+		value interface{}
+		width int
+		want  string
+	}{
+		{"unlimited", long, 0, `"` + long + `"`},
+		{"short value", "abc", 10, `"abc"`},
+		{"truncated", "abcdefghij", 5, `"abcd...`},
+		{"object", map[string]interface{}{"a": float64(1)}, 0, `{"a":1}`},
+		{"null", nil, 4, "null"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatValue(tt.value, tt.width); got != tt.want {
+				t.Errorf("formatValue() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatDiffOutputSupersetValue(t *testing.T) {
+	subset := map[string]interface{}{"name": "alice", "id": float64(1)}
+	superset := map[string]interface{}{"name": strings.Repeat("b", 60), "id": float64(1)}
+	_, diffs := CheckSubset(subset, superset)
+
+	full := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{})
+	if !strings.Contains(full, `-  "name": "alice" (superset: "`+strings.Repeat("b", 60)+`")`) {
+		t.Errorf("expected full superset value, got:\n%s", full)
+	}
+
+	short := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{ValueWidth: 8})
+	if !strings.Contains(short, `-  "name": "alice" (superset: "bbbbbbb...)`) {
+		t.Errorf("expected truncated superset value, got:\n%s", short)
+	}
+}
+
+func TestFormatDiffOutputExtraKeys(t *testing.T) {
+	subset := map[string]interface{}{
+		"user": map[string]interface{}{"name": "alice"},
+	}
+	superset := map[string]interface{}{
+		"user": map[string]interface{}{"name": "alice", "age": float64(30)},
+		"tags": []interface{}{"a"},
+	}
+
+	_, diffs := CheckSubsetWithOptions(subset, superset, Options{ShowExtra: true})
+	got := FormatDiffOutput(subset, diffs)
+	want := ` {
++  "tags": [
++    "a"
++  ],
+   "user": {
++    "age": 30,
+     "name": "alice"
+   }
+ }
+`
+	if got != want {
+		t.Errorf("FormatDiffOutput() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, ok := subset["tags"]; ok {
+		t.Error("rendering extras must not modify the subset")
+	}
+}
+
+func TestFormatDiffOutputMatched(t *testing.T) {
+	subset := map[string]interface{}{"name": "alice", "role": "admin"}
+	superset := map[string]interface{}{"name": "alice", "role": "user"}
+
+	_, diffs, matches := CheckSubsetExplain(subset, superset, Options{})
+	got := FormatDiffOutputWithOptions(subset, diffs, FormatOptions{Matched: matches})
+	want := ` {
+   "name": "alice", # ok
+-  "role": "admin" (superset: "user")
+ }
+`
+	if got != want {
+		t.Errorf("FormatDiffOutputWithOptions() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestShouldMarkAsDiffSegments(t *testing.T) {
+	subset := map[string]interface{}{
+		"a": map[string]interface{}{"b": float64(1), "bc": float64(2)},
+	}
+	superset := map[string]interface{}{
+		"a": map[string]interface{}{"b": float64(9), "bc": float64(2)},
+	}
+
+	_, diffs := CheckSubset(subset, superset)
+	got := FormatDiffOutput(subset, diffs)
+	want := ` {
+   "a": {
+-    "b": 1, (superset: 9)
+     "bc": 2
+   }
+ }
+`
+	if got != want {
+		t.Errorf("FormatDiffOutput() =\n%s\nwant\n%s", got, want)
+	}
+
+	paths := map[string]bool{"$['a']['b']": true, "$['list'][1]": true}
+	tests := []struct {
+		path spec.NormalizedPath
+		want bool
+	}{
+		{spec.NormalizedPath{spec.Name("a"), spec.Name("b")}, true},
+		{spec.NormalizedPath{spec.Name("a"), spec.Name("bc")}, false},
+		{spec.NormalizedPath{spec.Name("a")}, false},
+		{spec.NormalizedPath{spec.Name("list"), spec.Index(1), spec.Name("x")}, true},
+		{spec.NormalizedPath{spec.Name("list"), spec.Index(10)}, false},
+	}
+	for _, tt := range tests {
+		if got := shouldMarkAsDiff(tt.path, paths); got != tt.want {
+			t.Errorf("shouldMarkAsDiff(%s) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestAddSupersetLines(t *testing.T) {
+	lines := map[string]int{
+		"$":                    1,
+		"$['user']":            2,
+		"$['user']['name']":    3,
+		"$['user']['tags']":    4,
+		"$['user']['tags'][0]": 5,
+	}
+	diffs := []Diff{
+		{Path: spec.NormalizedPath{spec.Name("user"), spec.Name("name")}, Type: DiffValueMismatch},
+		{Path: spec.NormalizedPath{spec.Name("user"), spec.Name("email")}, Type: DiffMissingKey},
+		{Path: spec.NormalizedPath{spec.Name("user"), spec.Name("tags"), spec.Index(0)}, Type: DiffElementNotFound},
+		{Path: spec.NormalizedPath{spec.Name("other"), spec.Name("x")}, Type: DiffTypeMismatch},
+	}
+
+	AddSupersetLines(diffs, lines, nil)
+	for i, want := range []int{3, 2, 4, 1} {
+		if diffs[i].SupersetLine != want {
+			t.Errorf("diff %d (%s) line = %d, want %d", i, diffs[i].Path, diffs[i].SupersetLine, want)
+		}
+	}
+
+	got, err := FormatDiffJSON(diffs[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(got, `"superset_line": 3`) {
+		t.Errorf("FormatDiffJSON() = %s, want superset_line", got)
+	}
+}
+
+func TestFormatDiffOutputKeyOrder(t *testing.T) {
+	subset := map[string]interface{}{"b": float64(1), "a": float64(2), "c": float64(3)}
+	order := map[string][]string{"$": {"c", "a"}}
+
+	got := FormatDiffOutputWithOptions(subset, nil, FormatOptions{KeyOrder: order})
+	want := ` {
+   "c": 3,
+   "a": 2,
+   "b": 1
+ }
+`
+	if got != want {
+		t.Errorf("FormatDiffOutputWithOptions() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,139 @@
+package subset
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// maxFuzzInput bounds the combined size of the two documents in bytes
+const maxFuzzInput = 1024
+
+func FuzzCheckSubset(f *testing.F) {
+	seeds := [][2]string{
+		{`{"a": 1}`, `{"a": 1, "b": 2}`},
+		{`[1, 2, 2]`, `[2, 1]`},
+		{`{"users": [{"id": 1, "tags": ["x"]}]}`, `{"users": [{"id": 2}, {"id": 1, "tags": ["y", "x"]}]}`},
+		{`{"0": "a", "1": "b"}`, `["a", "b"]`},
+		{`"re:/^[0-9]+$/"`, `"123"`},
+		{`"contains:bc"`, `"abcd"`},
+		{`{"n": 12345678901234567890}`, `{"n": 1.2345678901234567e19}`},
+		{`{"*": {"x": null}}`, `{"k": {"x": ""}}`},
+		{`[[1, [2]], {"a": [{}]}]`, `[{"a": [{"b": 1}]}, [[2], 1]]`},
+		{`null`, `[]`},
+	}
+	for _, s := range seeds {
+		f.Add(s[0], s[1])
+	}
+
+	optionSets := []Options{
+		{},
+		{ArrayOrder: ArrayOrdered, ArrayExactLength: true},
+		{ArrayOrder: ArrayMultiset, ShowExtra: true},
+		{ArrayKey: "id", IgnoreKeyCase: true},
+		{EnableRegex: true, EnableMatchers: true, EnableWildcard: true},
+		{ArrayAsObject: true, AllowTypeWidening: true, NullMeansOptional: true},
+		{Epsilon: 0.5, IgnoreCase: true, TrimStrings: true, CoerceBool: true, EmptyEqualsNull: true},
+		{NormalizeNumbers: true, Intersection: true, IgnoreNullValues: true},
+		{StrictTypes: true, IgnoreValues: true},
+		{LimitDepth: true, MaxDepth: 1, FailFast: true},
+		{Parallel: 4},
+	}
+
+	f.Fuzz(func(t *testing.T, subsetJSON, supersetJSON string) {
+		// Large inputs add nothing the comparison has not seen in small
+		// ones, and make minimizing a new input run into its time limit.
+		if len(subsetJSON)+len(supersetJSON) > maxFuzzInput {
+			return
+		}
+		subset, ok := decodeFuzzJSON(subsetJSON)
+		if !ok {
+			return
+		}
+		superset, ok := decodeFuzzJSON(supersetJSON)
+		if !ok {
+			return
+		}
+
+		for _, opts := range optionSets {
+			isSubset, diffs := CheckSubsetWithOptions(subset, superset, opts)
+			if isSubset && !opts.ShowExtra && len(diffs) > 0 {
+				t.Errorf("CheckSubsetWithOptions(%+v) = true with diffs %+v", opts, diffs)
+			}
+			if !isSubset && len(diffs) == 0 {
+				t.Errorf("CheckSubsetWithOptions(%+v) = false without diffs", opts)
+			}
+			FormatDiffOutputWithOptions(subset, diffs, FormatOptions{ValueWidth: DefaultValueWidth})
+			FormatDiffSummary(diffs)
+			if _, err := FormatDiffJSON(diffs); err != nil {
+				t.Errorf("FormatDiffJSON() error = %v", err)
+			}
+			FormatDiffMerged(subset, diffs)
+			FormatDiffUnified(subset, diffs, "superset", "subset")
+			FormatDiffTable(diffs, FormatOptions{})
+			Extract(subset, superset, opts)
+		}
+
+		// Every document contains itself.
+		if ok, diffs := CheckSubset(subset, subset); !ok {
+			t.Errorf("CheckSubset(doc, doc) = false for %s, diffs: %+v", subsetJSON, diffs)
+		}
+	})
+}
+
+// decodeFuzzJSON decodes a single JSON document the way the command line
+// tool does, reporting false for invalid input
+func decodeFuzzJSON(s string) (interface{}, bool) {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil || dec.More() {
+		return nil, false
+	}
+	return v, true
+}
+
+// TestUnexpectedGoTypes feeds values json.Unmarshal never produces, as a
+// library caller might, and checks that nothing panics
+func TestUnexpectedGoTypes(t *testing.T) {
+	type point struct{ X []int }
+	values := []interface{}{
+		[]string{"a"},
+		map[string]string{"a": "b"},
+		point{[]int{1}},
+		&point{},
+		func() {},
+		make(chan int),
+		[2]int{1, 2},
+		struct{ A interface{} }{[]int{1}},
+	}
+	for _, v := range values {
+		for _, w := range values {
+			subset := map[string]interface{}{"a": v, "b": []interface{}{v}}
+			superset := map[string]interface{}{"a": w, "b": []interface{}{w}}
+			_, diffs := CheckSubset(subset, superset)
+			FormatDiffOutput(subset, diffs)
+			Extract(subset, superset, Options{})
+		}
+	}
+
+	tests := []struct {
+		name     string
+		subset   interface{}
+		superset interface{}
+		want     bool
+	}{
+		{"equal slices", []string{"a"}, []string{"a"}, true},
+		{"different slices", []string{"a"}, []string{"b"}, false},
+		{"equal maps", map[string]string{"a": "b"}, map[string]string{"a": "b"}, true},
+		{"equal structs with slices", point{[]int{1}}, point{[]int{1}}, true},
+		{"different types", []string{"a"}, []interface{}{"a"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := CheckSubset(tt.subset, tt.superset); got != tt.want {
+				t.Errorf("CheckSubset() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
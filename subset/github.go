@@ -0,0 +1,68 @@
+package subset
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FormatDiffGitHub renders each difference as a GitHub Actions workflow
+// command, so failures are annotated in the job log and pull request.
+// Extra keys become ::notice and everything else ::error. file names the
+// superset and is omitted when empty, as is the line when unknown.
+func FormatDiffGitHub(diffs []Diff, file string) string {
+	return FormatDiffGitHubWithOptions(diffs, file, FormatOptions{})
+}
+
+// FormatDiffGitHubWithOptions is FormatDiffGitHub with paths written in
+// opts.PathStyle. Other options do not apply.
+func FormatDiffGitHubWithOptions(diffs []Diff, file string, opts FormatOptions) string {
+	var sb strings.Builder
+	for _, d := range diffs {
+		command := "error"
+		if d.Type == DiffExtraKey {
+			command = "notice"
+		}
+
+		var props []string
+		if file != "" {
+			props = append(props, "file="+escapeGitHubProperty(file))
+		}
+		if d.SupersetLine > 0 {
+			props = append(props, fmt.Sprintf("line=%d", d.SupersetLine))
+		}
+		props = append(props, "title="+escapeGitHubProperty(diffTypeLabel(d.Type, 1)))
+
+		fmt.Fprintf(&sb, "::%s %s::%s\n", command, strings.Join(props, ","),
+			escapeGitHubData(FormatPath(d.Path, opts.PathStyle)+": "+describeDiff(d)))
+	}
+	return sb.String()
+}
+
+// describeDiff explains a difference in one line for annotations
+func describeDiff(d Diff) string {
+	if d.Message != "" {
+		return d.Message
+	}
+	switch d.Type {
+	case DiffMissingKey:
+		return "missing from superset, want " + formatValue(d.SubsetValue, DefaultValueWidth)
+	case DiffValueMismatch, DiffTypeMismatch:
+		return fmt.Sprintf("want %s, got %s", formatValue(d.SubsetValue, DefaultValueWidth), formatValue(d.SupersetValue, DefaultValueWidth))
+	case DiffElementNotFound:
+		return "no superset element matches " + formatValue(d.SubsetValue, DefaultValueWidth)
+	case DiffExtraKey:
+		return "only in superset: " + formatValue(d.SupersetValue, DefaultValueWidth)
+	default:
+		return diffTypeLabel(d.Type, 1)
+	}
+}
+
+// escapeGitHubData escapes the message of a workflow command
+func escapeGitHubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeGitHubProperty escapes a workflow command property value
+func escapeGitHubProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
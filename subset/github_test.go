@@ -0,0 +1,32 @@
+package subset
+
+import (
+	"testing"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+func TestFormatDiffGitHub(t *testing.T) {
+	diffs := []Diff{
+		{Path: spec.NormalizedPath{spec.Name("user"), spec.Name("email")}, Type: DiffMissingKey, SubsetValue: "a@example.com"},
+		{Path: spec.NormalizedPath{spec.Name("age")}, Type: DiffValueMismatch, SubsetValue: float64(30), SupersetValue: float64(31), SupersetLine: 4},
+		{Path: spec.NormalizedPath{spec.Name("tags"), spec.Index(0)}, Type: DiffElementNotFound, SubsetValue: "x", Message: "100%\nsure"},
+		{Path: spec.NormalizedPath{spec.Name("id")}, Type: DiffExtraKey, SupersetValue: float64(7)},
+	}
+
+	want := `::error file=out/a%2Cb.json,title=missing key::$['user']['email']: missing from superset, want "a@example.com"
+::error file=out/a%2Cb.json,line=4,title=value mismatch::$['age']: want 30, got 31
+::error file=out/a%2Cb.json,title=element not found::$['tags'][0]: 100%25%0Asure
+::notice file=out/a%2Cb.json,title=extra key::$['id']: only in superset: 7
+`
+	if got := FormatDiffGitHub(diffs, "out/a,b.json"); got != want {
+		t.Errorf("FormatDiffGitHub() =\n%s\nwant\n%s", got, want)
+	}
+
+	// Without a file or line those properties are left out.
+	diffs[1].SupersetLine = 0
+	want = "::error title=value mismatch::$['age']: want 30, got 31\n"
+	if got := FormatDiffGitHub(diffs[1:2], ""); got != want {
+		t.Errorf("FormatDiffGitHub() = %q, want %q", got, want)
+	}
+}
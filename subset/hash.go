@@ -0,0 +1,94 @@
+package subset
+
+import (
+	"encoding/json"
+	"math/big"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// checkHashedArraySubset is the set mode fast path for arrays of primitives.
+// The superset elements are indexed by primitiveKey so each subset element
+// is found in constant time. It reports handled=false, and does nothing, when
+// an element is not a primitive or an option makes equality inexact.
+func checkHashedArraySubset(subset, superset []interface{}, path spec.NormalizedPath, opts Options) (ok bool, diffs []Diff, handled bool) {
+	// Rules, embedded JSON and time fields may single out elements, so they
+	// have to be visited one by one.
+	if !exactPrimitives(opts) || len(opts.rules) > 0 || opts.embedded != nil || opts.timeFields != nil || (opts.LimitDepth && len(path) >= opts.MaxDepth) {
+		return false, nil, false
+	}
+
+	index := make(map[string]struct{}, len(superset))
+	for _, elem := range superset {
+		key, ok := primitiveKey(elem)
+		if !ok {
+			return false, nil, false
+		}
+		index[key] = struct{}{}
+	}
+	keys := make([]string, len(subset))
+	for i, elem := range subset {
+		key, ok := primitiveKey(elem)
+		if !ok {
+			return false, nil, false
+		}
+		keys[i] = key
+	}
+
+	isSubset := true
+	for i, key := range keys {
+		if opts.stop(isSubset) {
+			break
+		}
+		childPath := append(copyPath(path), spec.Index(i))
+		if opts.ignored.contains(childPath) {
+			continue
+		}
+		if opts.Stats != nil {
+			opts.Stats.Primitives++
+		}
+		opts.progress.add(1)
+		if _, found := index[key]; found {
+			opts.matches.add(childPath)
+			continue
+		}
+		isSubset = false
+		diffs = append(diffs, Diff{Path: childPath, Type: DiffElementNotFound, SubsetValue: subset[i]})
+	}
+	return isSubset, diffs, true
+}
+
+// exactPrimitives reports whether primitives are only equal when their
+// canonical encodings are, which is what hashing relies on. Keys encode
+// numbers as floats, which NormalizeNumbers and StrictTypes do not compare by.
+func exactPrimitives(opts Options) bool {
+	return opts.Epsilon == 0 && !opts.NormalizeNumbers && !opts.StrictTypes && !opts.IgnoreCase && !opts.TrimStrings && !opts.CoerceBool && !opts.EmptyEqualsNull && !opts.IgnoreValues && !opts.EnableRegex && !opts.EnableMatchers
+}
+
+// primitiveKey returns the canonical JSON encoding of a string, number,
+// bool or null. Numbers of any Go type are encoded as float64, matching
+// how checkPrimitive compares them. Integers and json.Numbers that float64
+// cannot represent exactly are compared exactly instead, so they fall back
+// to the slow path.
+func primitiveKey(v interface{}) (string, bool) {
+	switch v.(type) {
+	case nil, string, bool:
+	default:
+		f, ok := toFloat(v)
+		if !ok {
+			return "", false
+		}
+		if r, ok := exactNumber(v); ok {
+			if fr := new(big.Rat).SetFloat64(f); fr == nil || fr.Cmp(r) != 0 {
+				return "", false
+			}
+		}
+		v = f
+	}
+	// NaN and infinities cannot be encoded and fall back to the slow path.
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
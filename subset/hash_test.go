@@ -0,0 +1,82 @@
+package subset
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+func TestHashedArrayMatchesScan(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	values := []interface{}{nil, true, false, "a", "b", "1", float64(1), float64(2), 1.5, json.Number("2"), 3}
+
+	for n := 0; n < 200; n++ {
+		subset := make([]interface{}, rng.Intn(5))
+		for i := range subset {
+			subset[i] = values[rng.Intn(len(values))]
+		}
+		superset := make([]interface{}, rng.Intn(6))
+		for i := range superset {
+			superset[i] = values[rng.Intn(len(values))]
+		}
+
+		ok, diffs, handled := checkHashedArraySubset(subset, superset, spec.NormalizedPath{}, Options{})
+		if !handled {
+			t.Fatalf("primitive arrays %v and %v were not hashed", subset, superset)
+		}
+		wantOK, wantDiffs := scanSetArraySubset(subset, superset, spec.NormalizedPath{}, Options{})
+		if ok != wantOK || !reflect.DeepEqual(diffs, wantDiffs) {
+			t.Errorf("subset %v of %v: hashed = %v %+v, scan = %v %+v", subset, superset, ok, diffs, wantOK, wantDiffs)
+		}
+	}
+}
+
+func TestHashedArrayFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		subset   []interface{}
+		superset []interface{}
+		opts     Options
+	}{
+		{"objects", []interface{}{map[string]interface{}{}}, []interface{}{"a"}, Options{}},
+		{"nested array in superset", []interface{}{"a"}, []interface{}{[]interface{}{"a"}}, Options{}},
+		{"epsilon", []interface{}{float64(1)}, []interface{}{1.1}, Options{Epsilon: 0.2}},
+		{"ignore case", []interface{}{"A"}, []interface{}{"a"}, Options{IgnoreCase: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, handled := checkHashedArraySubset(tt.subset, tt.superset, spec.NormalizedPath{}, tt.opts); handled {
+				t.Error("expected fallback to the scan")
+			}
+		})
+	}
+}
+
+func benchmarkArrays(n int) ([]interface{}, []interface{}) {
+	subset := make([]interface{}, n)
+	superset := make([]interface{}, n)
+	for i := 0; i < n; i++ {
+		subset[i] = fmt.Sprintf("item-%d", n-1-i)
+		superset[i] = fmt.Sprintf("item-%d", i)
+	}
+	return subset, superset
+}
+
+func BenchmarkSetArrayHashed(b *testing.B) {
+	subset, superset := benchmarkArrays(10000)
+	for i := 0; i < b.N; i++ {
+		checkSetArraySubset(subset, superset, spec.NormalizedPath{}, Options{})
+	}
+}
+
+func BenchmarkSetArrayScan(b *testing.B) {
+	subset, superset := benchmarkArrays(10000)
+	for i := 0; i < b.N; i++ {
+		scanSetArraySubset(subset, superset, spec.NormalizedPath{}, Options{})
+	}
+}
@@ -0,0 +1,91 @@
+package subset
+
+import (
+	"fmt"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// checkKeyedArraySubset pairs each object subset element with the superset
+// element whose Options.ArrayKey value matches, and compares the pair.
+// Elements without the key must match some superset element as a whole.
+func checkKeyedArraySubset(subset, superset []interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	var diffs []Diff
+	isSubset := true
+
+	for i, subsetElem := range subset {
+		if opts.stop(isSubset) {
+			return false, diffs
+		}
+		childPath := append(copyPath(path), spec.Index(i))
+		if opts.ignored.contains(childPath) {
+			continue
+		}
+
+		id, keyed := elementKey(subsetElem, opts)
+		if !keyed {
+			found := false
+			for _, supersetElem := range superset {
+				if tryMatch(subsetElem, supersetElem, childPath, opts) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				isSubset = false
+				diffs = append(diffs, Diff{Path: childPath, Type: DiffElementNotFound, SubsetValue: subsetElem})
+				opts.stream.emit(diffs)
+			}
+			continue
+		}
+
+		j := findKeyedElement(id, superset, append(copyPath(childPath), spec.Name(opts.ArrayKey)), opts)
+		if j < 0 {
+			isSubset = false
+			diffs = append(diffs, Diff{
+				Path:        childPath,
+				Type:        DiffMissingKey,
+				SubsetValue: subsetElem,
+				Message:     fmt.Sprintf("no superset element has %q: %s", opts.ArrayKey, formatValue(id, 0)),
+			})
+			opts.stream.emit(diffs)
+			continue
+		}
+		ok, elemDiffs := checkSubsetPath(subsetElem, superset[j], childPath, opts)
+		if !ok {
+			isSubset = false
+		}
+		diffs = append(diffs, elemDiffs...)
+	}
+
+	return isSubset, diffs
+}
+
+// elementKey returns the Options.ArrayKey value of an object element
+func elementKey(elem interface{}, opts Options) (interface{}, bool) {
+	m, ok := toObject(elem)
+	if !ok {
+		return nil, false
+	}
+	key, ok := lookupKey(m, opts.ArrayKey, opts)
+	if !ok {
+		return nil, false
+	}
+	return m[key], true
+}
+
+// findKeyedElement returns the index of the first superset element whose
+// key value equals id, or -1. Ids are compared exactly, without rules, so
+// options such as IgnoreValues cannot pair an element with the wrong one.
+func findKeyedElement(id interface{}, superset []interface{}, keyPath spec.NormalizedPath, opts Options) int {
+	idOpts := opts
+	idOpts.matches = nil
+	idOpts.rules = nil
+	exactOptions(&idOpts)
+	for j, elem := range superset {
+		if supersetID, ok := elementKey(elem, opts); ok && tryMatch(id, supersetID, keyPath, idOpts) {
+			return j
+		}
+	}
+	return -1
+}
@@ -0,0 +1,140 @@
+package subset
+
+import "testing"
+
+func TestCheckKeyedArraySubset(t *testing.T) {
+	superset := map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"id": float64(1), "name": "alice", "role": "admin"},
+			map[string]interface{}{"id": float64(2), "name": "bob", "role": "user"},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		subset    interface{}
+		wantOK    bool
+		wantPaths []string
+		wantTypes []DiffType
+	}{
+		{
+			name: "matching pairs in any order",
+			subset: map[string]interface{}{"users": []interface{}{
+				map[string]interface{}{"id": float64(2), "role": "user"},
+				map[string]interface{}{"id": float64(1)},
+			}},
+			wantOK: true,
+		},
+		{
+			name: "field mismatch in the paired element",
+			subset: map[string]interface{}{"users": []interface{}{
+				map[string]interface{}{"id": float64(2), "role": "admin"},
+			}},
+			wantPaths: []string{"$['users'][0]['role']"},
+			wantTypes: []DiffType{DiffValueMismatch},
+		},
+		{
+			name: "no element with the key value",
+			subset: map[string]interface{}{"users": []interface{}{
+				map[string]interface{}{"id": float64(3)},
+			}},
+			wantPaths: []string{"$['users'][0]"},
+			wantTypes: []DiffType{DiffMissingKey},
+		},
+		{
+			name: "element without the key is matched whole",
+			subset: map[string]interface{}{"users": []interface{}{
+				map[string]interface{}{"name": "bob"},
+				map[string]interface{}{"name": "carol"},
+			}},
+			wantPaths: []string{"$['users'][1]"},
+			wantTypes: []DiffType{DiffElementNotFound},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, diffs := CheckSubsetWithOptions(tt.subset, superset, Options{ArrayKey: "id"})
+			if ok != tt.wantOK {
+				t.Errorf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if len(diffs) != len(tt.wantPaths) {
+				t.Fatalf("got %d diffs, want %d: %+v", len(diffs), len(tt.wantPaths), diffs)
+			}
+			for i, d := range diffs {
+				if d.Path.String() != tt.wantPaths[i] || d.Type != tt.wantTypes[i] {
+					t.Errorf("diff %d = %s %v, want %s %v", i, d.Path, d.Type, tt.wantPaths[i], tt.wantTypes[i])
+				}
+			}
+		})
+	}
+}
+
+func TestKeyRule(t *testing.T) {
+	rules, err := ParseRules(map[string]string{"$.users": "key:id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subset := map[string]interface{}{"users": []interface{}{
+		map[string]interface{}{"id": "u1", "active": true},
+	}}
+	superset := map[string]interface{}{"users": []interface{}{
+		map[string]interface{}{"id": "u1", "active": false},
+	}}
+
+	_, diffs := CheckSubsetWithOptions(subset, superset, Options{Rules: rules})
+	if len(diffs) != 1 || diffs[0].Path.String() != "$['users'][0]['active']" {
+		t.Errorf("diffs = %+v, want a mismatch at $['users'][0]['active']", diffs)
+	}
+
+	_, diffs = CheckSubset(subset, superset)
+	if len(diffs) != 1 || diffs[0].Type != DiffElementNotFound {
+		t.Errorf("without the rule, diffs = %+v, want one element not found", diffs)
+	}
+}
+
+func TestKeyedIDsCompareExactly(t *testing.T) {
+	typeRule, err := ParseRules(map[string]string{"$.users[*].id": "type"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	superset := map[string]interface{}{"users": []interface{}{
+		map[string]interface{}{"id": float64(1), "role": "admin"},
+		map[string]interface{}{"id": float64(2), "role": "user"},
+	}}
+	subset := map[string]interface{}{"users": []interface{}{
+		map[string]interface{}{"id": float64(2), "role": "user"},
+	}}
+
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"type rule on the id", Options{Rules: typeRule}},
+		{"epsilon", Options{Epsilon: 1.5}},
+		{"ignore values", Options{IgnoreValues: true, ShowExtra: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.ArrayKey = "id"
+			ok, diffs := CheckSubsetWithOptions(subset, superset, tt.opts)
+			if !ok {
+				t.Fatalf("CheckSubsetWithOptions() = false, diffs: %+v", diffs)
+			}
+			// An extra key would show up if the element were paired with id 1.
+			for _, d := range diffs {
+				t.Errorf("unexpected diff %s %v", d.Path, d.Type)
+			}
+		})
+	}
+
+	// Ids equal only under IgnoreCase are not paired either.
+	ok, _ := CheckSubsetWithOptions(
+		map[string]interface{}{"users": []interface{}{map[string]interface{}{"id": "A"}}},
+		map[string]interface{}{"users": []interface{}{map[string]interface{}{"id": "a"}}},
+		Options{ArrayKey: "id", IgnoreCase: true})
+	if ok {
+		t.Error("ids differing in case should not pair")
+	}
+}
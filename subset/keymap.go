@@ -0,0 +1,55 @@
+package subset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// KeyMapping renames the subset keys its pattern selects, so their values
+// are looked up under Key in the superset
+type KeyMapping struct {
+	Pattern PathPattern
+	Key     string
+}
+
+// ParseKeyMapping parses "pattern=key", where pattern is a key glob or
+// JSONPath selecting subset keys, such as "userName=username" or
+// "$.user.userName=username"
+func ParseKeyMapping(s string) (KeyMapping, error) {
+	i := strings.LastIndex(s, "=")
+	if i <= 0 || i == len(s)-1 {
+		return KeyMapping{}, fmt.Errorf("invalid key mapping %q (want subsetKey=supersetKey)", s)
+	}
+	p, err := ParsePathPattern(s[:i])
+	if err != nil {
+		return KeyMapping{}, err
+	}
+	return KeyMapping{Pattern: p, Key: s[i+1:]}, nil
+}
+
+// resolvedMapping is a KeyMapping with its pattern resolved against the subset
+type resolvedMapping struct {
+	paths *pathSet
+	key   string
+}
+
+func resolveKeyMap(mappings []KeyMapping, doc interface{}) []resolvedMapping {
+	resolved := make([]resolvedMapping, 0, len(mappings))
+	for _, m := range mappings {
+		resolved = append(resolved, resolvedMapping{paths: newPathSet([]PathPattern{m.Pattern}, doc), key: m.Key})
+	}
+	return resolved
+}
+
+// mappedKey returns the superset key to look up for the subset key at path,
+// and whether a mapping renamed it. The first matching mapping wins.
+func mappedKey(path spec.NormalizedPath, opts Options) (string, bool) {
+	for _, m := range opts.keyMap {
+		if m.paths.contains(path) {
+			return m.key, true
+		}
+	}
+	return string(path[len(path)-1].(spec.Name)), false
+}
@@ -0,0 +1,48 @@
+package subset
+
+import "testing"
+
+func TestKeyMap(t *testing.T) {
+	subset := map[string]interface{}{
+		"user":  map[string]interface{}{"userName": "alice"},
+		"owner": map[string]interface{}{"userName": "bob"},
+	}
+	superset := map[string]interface{}{
+		"user":  map[string]interface{}{"username": "alice"},
+		"owner": map[string]interface{}{"userName": "bob"},
+	}
+
+	if ok, _ := CheckSubset(subset, superset); ok {
+		t.Fatal("the renamed key should not match without a mapping")
+	}
+
+	mapping, err := ParseKeyMapping("$.user.userName=username")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, diffs := CheckSubsetWithOptions(subset, superset, Options{KeyMap: []KeyMapping{mapping}}); !ok {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+
+	// A key glob renames the key at every depth, including $.owner where
+	// the superset still uses the old name.
+	mapping, err = ParseKeyMapping("userName=username")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, diffs := CheckSubsetWithOptions(subset, superset, Options{KeyMap: []KeyMapping{mapping}})
+	if len(diffs) != 1 || diffs[0].Path.String() != "$['owner']['userName']" || diffs[0].Type != DiffMissingKey {
+		t.Fatalf("diffs = %+v, want a missing key at $['owner']['userName']", diffs)
+	}
+	if want := `mapped to superset key "username"`; diffs[0].Message != want {
+		t.Errorf("message = %q, want %q", diffs[0].Message, want)
+	}
+}
+
+func TestParseKeyMappingErrors(t *testing.T) {
+	for _, s := range []string{"userName", "=username", "userName=", "$.[=x"} {
+		if _, err := ParseKeyMapping(s); err == nil {
+			t.Errorf("ParseKeyMapping(%q) should fail", s)
+		}
+	}
+}
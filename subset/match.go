@@ -0,0 +1,115 @@
+package subset
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+const regexPrefix = "re:"
+
+// regexPattern extracts the pattern from a subset value like "re:/^v[0-9]+$/"
+func regexPattern(value interface{}) (string, bool) {
+	s, ok := value.(string)
+	if !ok || !strings.HasPrefix(s, regexPrefix) {
+		return "", false
+	}
+
+	pattern := strings.TrimPrefix(s, regexPrefix)
+	if len(pattern) >= 2 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		pattern = pattern[1 : len(pattern)-1]
+	}
+	return pattern, true
+}
+
+// checkRegex matches a superset string against a subset pattern
+func checkRegex(pattern string, superset interface{}, path spec.NormalizedPath) (bool, []Diff) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return false, []Diff{{
+			Path:          copyPath(path),
+			Type:          DiffValueMismatch,
+			SubsetValue:   regexPrefix + "/" + pattern + "/",
+			SupersetValue: superset,
+			Message:       fmt.Sprintf("invalid pattern /%s/: %v", pattern, err),
+		}}
+	}
+
+	if s, ok := superset.(string); ok && re.MatchString(s) {
+		return true, nil
+	}
+	return false, []Diff{{
+		Path:          copyPath(path),
+		Type:          DiffValueMismatch,
+		SubsetValue:   regexPrefix + "/" + pattern + "/",
+		SupersetValue: superset,
+		Message:       fmt.Sprintf("does not match pattern /%s/", pattern),
+	}}
+}
+
+// matcher checks a superset value against the argument of a subset string
+// like "contains:error". It returns a description of the failure, or "".
+type matcher func(arg string, superset interface{}) string
+
+// matchers are the subset string prefixes recognized with EnableMatchers
+var matchers = map[string]matcher{
+	"contains:": matchContains,
+	"glob:":     matchGlob,
+}
+
+// checkMatcher applies the matcher named by a subset string's prefix.
+// handled is false if the value is not a matcher directive.
+func checkMatcher(subset, superset interface{}, path spec.NormalizedPath) (ok bool, diffs []Diff, handled bool) {
+	s, isString := subset.(string)
+	if !isString {
+		return false, nil, false
+	}
+	for prefix, m := range matchers {
+		if !strings.HasPrefix(s, prefix) {
+			continue
+		}
+		if msg := m(strings.TrimPrefix(s, prefix), superset); msg != "" {
+			return false, []Diff{{
+				Path:          copyPath(path),
+				Type:          DiffValueMismatch,
+				SubsetValue:   subset,
+				SupersetValue: superset,
+				Message:       msg,
+			}}, true
+		}
+		return true, nil, true
+	}
+	return false, nil, false
+}
+
+// matchContains requires the superset to be a string containing arg
+func matchContains(arg string, superset interface{}) string {
+	s, ok := superset.(string)
+	if !ok {
+		return fmt.Sprintf("is not a string, so cannot contain %q", arg)
+	}
+	if !strings.Contains(s, arg) {
+		return fmt.Sprintf("does not contain %q", arg)
+	}
+	return ""
+}
+
+// matchGlob requires the superset to be a string matching the path.Match
+// pattern arg, so "glob:*.example.com" accepts "api.example.com"
+func matchGlob(arg string, superset interface{}) string {
+	s, ok := superset.(string)
+	if !ok {
+		return fmt.Sprintf("is not a string, so cannot match glob %q", arg)
+	}
+	matched, err := path.Match(arg, s)
+	if err != nil {
+		return fmt.Sprintf("invalid glob %q: %v", arg, err)
+	}
+	if !matched {
+		return fmt.Sprintf("does not match glob %q", arg)
+	}
+	return ""
+}
@@ -0,0 +1,219 @@
+package subset
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRegexMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		subset      interface{}
+		superset    interface{}
+		opts        Options
+		wantSubset  bool
+		wantMessage string
+	}{
+		{
+			name:       "pattern matches",
+			subset:     map[string]interface{}{"version": "re:/^v[0-9]+$/"},
+			superset:   map[string]interface{}{"version": "v12"},
+			opts:       Options{EnableRegex: true},
+			wantSubset: true,
+		},
+		{
+			name:        "pattern does not match",
+			subset:      map[string]interface{}{"version": "re:/^v[0-9]+$/"},
+			superset:    map[string]interface{}{"version": "12"},
+			opts:        Options{EnableRegex: true},
+			wantSubset:  false,
+			wantMessage: "does not match pattern /^v[0-9]+$/",
+		},
+		{
+			name:        "non-string superset",
+			subset:      map[string]interface{}{"version": "re:/^[0-9]+$/"},
+			superset:    map[string]interface{}{"version": float64(12)},
+			opts:        Options{EnableRegex: true},
+			wantSubset:  false,
+			wantMessage: "does not match pattern",
+		},
+		{
+			name:        "invalid pattern",
+			subset:      map[string]interface{}{"version": "re:/v[0-9/"},
+			superset:    map[string]interface{}{"version": "v1"},
+			opts:        Options{EnableRegex: true},
+			wantSubset:  false,
+			wantMessage: "invalid pattern",
+		},
+		{
+			name:       "literal when disabled",
+			subset:     map[string]interface{}{"version": "re:/^v[0-9]+$/"},
+			superset:   map[string]interface{}{"version": "re:/^v[0-9]+$/"},
+			wantSubset: true,
+		},
+		{
+			name:       "not a pattern when disabled",
+			subset:     map[string]interface{}{"version": "re:/^v[0-9]+$/"},
+			superset:   map[string]interface{}{"version": "v12"},
+			wantSubset: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Fatalf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+			if tt.wantMessage != "" && !strings.Contains(diffs[0].Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", diffs[0].Message, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestContainsMatcher(t *testing.T) {
+	tests := []struct {
+		name        string
+		subset      interface{}
+		superset    interface{}
+		opts        Options
+		wantSubset  bool
+		wantMessage string
+	}{
+		{
+			name:       "substring present",
+			subset:     map[string]interface{}{"log": "contains:error"},
+			superset:   map[string]interface{}{"log": "fatal error: disk full"},
+			opts:       Options{EnableMatchers: true},
+			wantSubset: true,
+		},
+		{
+			name:        "substring absent",
+			subset:      map[string]interface{}{"log": "contains:error"},
+			superset:    map[string]interface{}{"log": "all good"},
+			opts:        Options{EnableMatchers: true},
+			wantSubset:  false,
+			wantMessage: `does not contain "error"`,
+		},
+		{
+			name:        "superset not a string",
+			subset:      map[string]interface{}{"log": "contains:1"},
+			superset:    map[string]interface{}{"log": float64(1)},
+			opts:        Options{EnableMatchers: true},
+			wantSubset:  false,
+			wantMessage: `is not a string, so cannot contain "1"`,
+		},
+		{
+			name:       "literal when disabled",
+			subset:     map[string]interface{}{"log": "contains:error"},
+			superset:   map[string]interface{}{"log": "contains:error"},
+			wantSubset: true,
+		},
+		{
+			name:       "no substring match when disabled",
+			subset:     map[string]interface{}{"log": "contains:error"},
+			superset:   map[string]interface{}{"log": "an error"},
+			wantSubset: false,
+		},
+		{
+			name:       "array elements",
+			subset:     []interface{}{"contains:warn"},
+			superset:   []interface{}{"info: ok", "warning: slow"},
+			opts:       Options{EnableMatchers: true},
+			wantSubset: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Fatalf("CheckSubsetWithOptions() = %v, want %v; diffs: %+v", got, tt.wantSubset, diffs)
+			}
+			if tt.wantMessage != "" {
+				if len(diffs) != 1 || diffs[0].Type != DiffValueMismatch || diffs[0].Message != tt.wantMessage {
+					t.Errorf("diffs = %+v, want one value mismatch with %q", diffs, tt.wantMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestGlobMatcher(t *testing.T) {
+	tests := []struct {
+		name        string
+		subset      interface{}
+		superset    interface{}
+		opts        Options
+		wantSubset  bool
+		wantMessage string
+	}{
+		{
+			name:       "glob matches",
+			subset:     map[string]interface{}{"host": "glob:*.example.com"},
+			superset:   map[string]interface{}{"host": "api.example.com"},
+			opts:       Options{EnableMatchers: true},
+			wantSubset: true,
+		},
+		{
+			name:       "character class",
+			subset:     map[string]interface{}{"id": "glob:v[0-9]?"},
+			superset:   map[string]interface{}{"id": "v1a"},
+			opts:       Options{EnableMatchers: true},
+			wantSubset: true,
+		},
+		{
+			name:        "glob does not match",
+			subset:      map[string]interface{}{"host": "glob:*.example.com"},
+			superset:    map[string]interface{}{"host": "example.org"},
+			opts:        Options{EnableMatchers: true},
+			wantSubset:  false,
+			wantMessage: `does not match glob "*.example.com"`,
+		},
+		{
+			name:        "star does not cross slash",
+			subset:      map[string]interface{}{"path": "glob:/api/*"},
+			superset:    map[string]interface{}{"path": "/api/v1/users"},
+			opts:        Options{EnableMatchers: true},
+			wantSubset:  false,
+			wantMessage: `does not match glob "/api/*"`,
+		},
+		{
+			name:        "invalid glob",
+			subset:      map[string]interface{}{"host": "glob:[a-"},
+			superset:    map[string]interface{}{"host": "a"},
+			opts:        Options{EnableMatchers: true},
+			wantSubset:  false,
+			wantMessage: `invalid glob "[a-": syntax error in pattern`,
+		},
+		{
+			name:        "superset not a string",
+			subset:      map[string]interface{}{"port": "glob:80*"},
+			superset:    map[string]interface{}{"port": float64(8080)},
+			opts:        Options{EnableMatchers: true},
+			wantSubset:  false,
+			wantMessage: `is not a string, so cannot match glob "80*"`,
+		},
+		{
+			name:       "literal when disabled",
+			subset:     map[string]interface{}{"host": "glob:*.example.com"},
+			superset:   map[string]interface{}{"host": "api.example.com"},
+			wantSubset: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Fatalf("CheckSubsetWithOptions() = %v, want %v; diffs: %+v", got, tt.wantSubset, diffs)
+			}
+			if tt.wantMessage != "" {
+				if len(diffs) != 1 || diffs[0].Type != DiffValueMismatch || diffs[0].Message != tt.wantMessage {
+					t.Errorf("diffs = %+v, want one value mismatch with %q", diffs, tt.wantMessage)
+				}
+			}
+		})
+	}
+}
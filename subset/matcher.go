@@ -0,0 +1,79 @@
+package subset
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Matcher compares raw JSON documents, for example from test helpers:
+//
+//	if ok, diff := subset.MatchJSON(expected, body); !ok {
+//		t.Errorf("response does not contain expected fields:\n%s", diff)
+//	}
+type Matcher struct {
+	Options Options
+}
+
+// MatchJSON reports whether the subset document is contained in the superset
+// document using the default options.
+func MatchJSON(subset, superset []byte) (bool, string) {
+	return Matcher{}.Match(subset, superset)
+}
+
+// Match reports whether the subset document is contained in the superset
+// document. On failure the string holds the formatted diff, or the reason a
+// document could not be decoded.
+func (m Matcher) Match(subset, superset []byte) (bool, string) {
+	subsetData, err := decodeDocument(subset)
+	if err != nil {
+		return false, fmt.Sprintf("invalid subset JSON: %v", err)
+	}
+	supersetData, err := decodeDocument(superset)
+	if err != nil {
+		return false, fmt.Sprintf("invalid superset JSON: %v", err)
+	}
+
+	ok, diffs := CheckSubsetWithOptions(subsetData, supersetData, m.Options)
+	if ok {
+		return true, ""
+	}
+	return false, FormatDiffOutput(subsetData, diffs)
+}
+
+// CheckSubsetBytes decodes two JSON documents and checks if subset is a
+// subset of superset, returning the diffs rather than formatting them.
+// It fails if either document is not valid JSON.
+func CheckSubsetBytes(subset, superset []byte, opts Options) (bool, []Diff, error) {
+	subsetData, err := decodeDocument(subset)
+	if err != nil {
+		return false, nil, fmt.Errorf("invalid subset JSON: %w", err)
+	}
+	supersetData, err := decodeDocument(superset)
+	if err != nil {
+		return false, nil, fmt.Errorf("invalid superset JSON: %w", err)
+	}
+
+	ok, diffs := CheckSubsetWithOptions(subsetData, supersetData, opts)
+	return ok, diffs, nil
+}
+
+// decodeDocument decodes a single JSON document as the command line tool
+// does, keeping numbers as json.Number so integers beyond float64
+// precision compare exactly
+func decodeDocument(data []byte) (interface{}, error) {
+	var doc interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid data after top-level value at offset %d", dec.InputOffset())
+	}
+	return doc, nil
+}
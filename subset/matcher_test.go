@@ -0,0 +1,107 @@
+package subset
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		subset   string
+		superset string
+		wantOK   bool
+		wantDiff string
+	}{
+		{
+			name:     "contained",
+			subset:   `{"user": {"name": "alice"}}`,
+			superset: `{"user": {"name": "alice", "age": 30}}`,
+			wantOK:   true,
+		},
+		{
+			name:     "missing key",
+			subset:   `{"user": {"email": "alice@example.com"}}`,
+			superset: `{"user": {"name": "alice"}}`,
+			wantDiff: `-    "email": "alice@example.com"`,
+		},
+		{
+			name:     "invalid subset",
+			subset:   `{`,
+			superset: `{}`,
+			wantDiff: "invalid subset JSON",
+		},
+		{
+			name:     "invalid superset",
+			subset:   `{}`,
+			superset: `[`,
+			wantDiff: "invalid superset JSON",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, diff := MatchJSON([]byte(tt.subset), []byte(tt.superset))
+			if ok != tt.wantOK {
+				t.Fatalf("MatchJSON() = %v, want %v", ok, tt.wantOK)
+			}
+			if tt.wantOK && diff != "" {
+				t.Errorf("MatchJSON() diff = %q, want empty", diff)
+			}
+			if !strings.Contains(diff, tt.wantDiff) {
+				t.Errorf("MatchJSON() diff = %q, want it to contain %q", diff, tt.wantDiff)
+			}
+		})
+	}
+}
+
+func TestMatchJSONLargeIntegers(t *testing.T) {
+	// 2^53 + 1 rounds to 2^53 as a float64.
+	if ok, _ := MatchJSON([]byte(`{"id": 9007199254740993}`), []byte(`{"id": 9007199254740992}`)); ok {
+		t.Error("MatchJSON() = true for IDs above 2^53 that differ by one")
+	}
+}
+
+func TestMatcherOptions(t *testing.T) {
+	m := Matcher{Options: Options{IgnoreCase: true}}
+	if ok, diff := m.Match([]byte(`{"a": "X"}`), []byte(`{"a": "x"}`)); !ok {
+		t.Errorf("Match() = false with IgnoreCase, diff:\n%s", diff)
+	}
+}
+func TestCheckSubsetBytes(t *testing.T) {
+	tests := []struct {
+		name      string
+		subset    string
+		superset  string
+		wantOK    bool
+		wantDiffs int
+		wantErr   string
+	}{
+		{"subset", `{"a": 1}`, `{"a": 1, "b": 2}`, true, 0, ""},
+		{"not a subset", `{"a": 1, "c": 3}`, `{"a": 2}`, false, 2, ""},
+		{"invalid subset", `{"a": `, `{}`, false, 0, "invalid subset JSON: "},
+		{"invalid superset", `{}`, `[1,]`, false, 0, "invalid superset JSON: "},
+		{"empty input", ``, `{}`, false, 0, "invalid subset JSON: "},
+		{"trailing data", `{} {}`, `{}`, false, 0, "invalid subset JSON: "},
+		{"large IDs differ", `{"id": 9007199254740993}`, `{"id": 9007199254740992}`, false, 1, ""},
+		{"large IDs equal", `{"id": 9007199254740993}`, `{"id": 9007199254740993, "b": 2}`, true, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, diffs, err := CheckSubsetBytes([]byte(tt.subset), []byte(tt.superset), Options{})
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want prefix %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ok != tt.wantOK || len(diffs) != tt.wantDiffs {
+				t.Errorf("got ok=%v with %d diffs, want ok=%v with %d", ok, len(diffs), tt.wantOK, tt.wantDiffs)
+			}
+		})
+	}
+}
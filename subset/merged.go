@@ -0,0 +1,46 @@
+package subset
+
+import (
+	"encoding/json"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// Keys of the objects FormatDiffMerged puts in place of differing values
+const (
+	mergedSubsetKey   = "__subset__"
+	mergedSupersetKey = "__superset__"
+)
+
+// FormatDiffMerged renders the subset as indented JSON with every
+// difference replaced by an object holding both sides, such as
+// {"__subset__": 1, "__superset__": 2}. Missing keys and elements only have
+// "__subset__", extra keys only "__superset__". A value replaced as a whole,
+// like an array of the wrong length, hides the diffs nested inside it.
+func FormatDiffMerged(subset interface{}, diffs []Diff) (string, error) {
+	merged := subset
+	var replaced []spec.NormalizedPath
+	for _, d := range diffs {
+		if _, ok := enclosingPath(d.Path, replaced); ok {
+			continue
+		}
+		sides := make(map[string]interface{}, 2)
+		switch d.Type {
+		case DiffMissingKey, DiffElementNotFound:
+			sides[mergedSubsetKey] = d.SubsetValue
+		case DiffExtraKey:
+			sides[mergedSupersetKey] = d.SupersetValue
+		default:
+			sides[mergedSubsetKey] = d.SubsetValue
+			sides[mergedSupersetKey] = d.SupersetValue
+		}
+		merged = insertValue(merged, d.Path, sides)
+		replaced = append(replaced, d.Path)
+	}
+
+	data, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
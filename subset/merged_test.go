@@ -0,0 +1,83 @@
+package subset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestFormatDiffMerged(t *testing.T) {
+	tests := []struct {
+		name     string
+		subset   string
+		superset string
+		opts     Options
+		want     string
+	}{
+		{
+			name:     "value mismatch",
+			subset:   `{"a": 1, "b": {"c": "x"}}`,
+			superset: `{"a": 2, "b": {"c": "x"}}`,
+			want:     `{"a": {"__subset__": 1, "__superset__": 2}, "b": {"c": "x"}}`,
+		},
+		{
+			name:     "missing key and element",
+			subset:   `{"user": {"name": "alice"}, "tags": ["admin"]}`,
+			superset: `{"user": {}, "tags": ["dev"]}`,
+			want:     `{"user": {"name": {"__subset__": "alice"}}, "tags": [{"__subset__": "admin"}]}`,
+		},
+		{
+			name:     "type mismatch",
+			subset:   `{"a": {"b": 1}}`,
+			superset: `{"a": [1]}`,
+			want:     `{"a": {"__subset__": {"b": 1}, "__superset__": [1]}}`,
+		},
+		{
+			name:     "extra key",
+			subset:   `{"a": 1}`,
+			superset: `{"a": 1, "b": true}`,
+			opts:     Options{ShowExtra: true},
+			want:     `{"a": 1, "b": {"__superset__": true}}`,
+		},
+		{
+			name:     "length mismatch hides nested diffs",
+			subset:   `{"ids": [1, 3]}`,
+			superset: `{"ids": [1, 2, 4]}`,
+			opts:     Options{ArrayExactLength: true},
+			want:     `{"ids": {"__subset__": [1, 3], "__superset__": [1, 2, 4]}}`,
+		},
+		{
+			name:     "no differences",
+			subset:   `{"a": [1]}`,
+			superset: `{"a": [1, 2]}`,
+			want:     `{"a": [1]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var subset, superset, want interface{}
+			for _, v := range []struct {
+				doc string
+				to  *interface{}
+			}{{tt.subset, &subset}, {tt.superset, &superset}, {tt.want, &want}} {
+				if err := json.Unmarshal([]byte(v.doc), v.to); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, diffs := CheckSubsetWithOptions(subset, superset, tt.opts)
+			output, err := FormatDiffMerged(subset, diffs)
+			if err != nil {
+				t.Fatalf("FormatDiffMerged() error = %v", err)
+			}
+			var got interface{}
+			if err := json.Unmarshal([]byte(output), &got); err != nil {
+				t.Fatalf("output is not valid JSON: %v\n%s", err, output)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("FormatDiffMerged() = %s, want %s", output, tt.want)
+			}
+		})
+	}
+}
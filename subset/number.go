@@ -0,0 +1,168 @@
+package subset
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// maxExactExponent bounds the decimal exponent of a json.Number compared
+// exactly. Beyond it, as in 1e-888880, the rational would need hundreds of
+// thousands of digits, so the number is compared as a float64 instead.
+const maxExactExponent = 1000
+
+// toFloat converts any Go numeric type or json.Number into a float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// numberSpelling returns the literal a number is written as: a json.Number
+// as decoded, and other numeric types in their shortest formatting, so
+// float64(1) from YAML or TOML is spelled "1" like the JSON literal 1
+func numberSpelling(v interface{}) (string, bool) {
+	switch n := v.(type) {
+	case json.Number:
+		return string(n), true
+	case float64:
+		return strconv.FormatFloat(n, 'g', -1, 64), true
+	case float32:
+		return strconv.FormatFloat(float64(n), 'g', -1, 32), true
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(n), true
+	default:
+		return "", false
+	}
+}
+
+// exactNumber converts an integer or json.Number into an exact rational.
+// Floats are left out: a float64 decoded from "0.1" is not exactly 1/10,
+// and so are json.Numbers with an exponent beyond maxExactExponent.
+func exactNumber(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case int:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int8:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int16:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int32:
+		return new(big.Rat).SetInt64(int64(n)), true
+	case int64:
+		return new(big.Rat).SetInt64(n), true
+	case uint:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint8:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint16:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint32:
+		return new(big.Rat).SetUint64(uint64(n)), true
+	case uint64:
+		return new(big.Rat).SetUint64(n), true
+	case json.Number:
+		if !exponentInRange(string(n)) {
+			return nil, false
+		}
+		return new(big.Rat).SetString(string(n))
+	default:
+		return nil, false
+	}
+}
+
+// exponentInRange reports whether the exponent of a number literal, if it
+// has one, is within maxExactExponent
+func exponentInRange(s string) bool {
+	i := strings.IndexAny(s, "eE")
+	if i < 0 {
+		return true
+	}
+	exp, err := strconv.Atoi(s[i+1:])
+	return err == nil && exp >= -maxExactExponent && exp <= maxExactExponent
+}
+
+// numbersEqual compares two numbers, reporting ok=false if either is not
+// a number. Without an epsilon, integers and json.Numbers are compared
+// exactly, so large IDs that round to the same float64 still differ.
+func numbersEqual(a, b interface{}, epsilon float64) (equal, ok bool) {
+	if epsilon == 0 {
+		// The same literal is equal even when it is too large to compare.
+		if na, ok := a.(json.Number); ok && na == b {
+			return true, true
+		}
+		if ra, ok := exactNumber(a); ok {
+			if rb, ok := exactNumber(b); ok {
+				return ra.Cmp(rb) == 0, true
+			}
+		}
+	}
+	fa, ok := toFloat(a)
+	if !ok {
+		return false, false
+	}
+	fb, ok := toFloat(b)
+	if !ok {
+		return false, false
+	}
+	return math.Abs(fa-fb) <= epsilon, true
+}
+
+// decimalNumber returns the decimal value of a number as NormalizeNumbers
+// sees it. Floats are taken at their shortest decimal spelling, so the
+// float64 0.1 is exactly 1/10 rather than the binary fraction it stores.
+func decimalNumber(v interface{}) (*big.Rat, bool) {
+	switch n := v.(type) {
+	case float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(n, 'g', -1, 64))
+	case float32:
+		return new(big.Rat).SetString(strconv.FormatFloat(float64(n), 'g', -1, 32))
+	default:
+		return exactNumber(v)
+	}
+}
+
+// decimalsEqual compares two numbers by decimal value, reporting ok=false
+// if either is not a finite number
+func decimalsEqual(a, b interface{}) (equal, ok bool) {
+	ra, ok := decimalNumber(a)
+	if !ok {
+		return false, false
+	}
+	rb, ok := decimalNumber(b)
+	if !ok {
+		return false, false
+	}
+	return ra.Cmp(rb) == 0, true
+}
@@ -0,0 +1,184 @@
+package subset
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNumericTypes(t *testing.T) {
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		opts       Options
+		wantSubset bool
+	}{
+		{"json.Number and float64", json.Number("30"), float64(30), Options{}, true},
+		{"float64 and json.Number", float64(1.5), json.Number("1.5"), Options{}, true},
+		{"json.Number forms", json.Number("1.0"), json.Number("1"), Options{}, true},
+		{"int and float64", 42, float64(42), Options{}, true},
+		{"int64 and json.Number", int64(7), json.Number("7"), Options{}, true},
+		{"uint8 and int", uint8(3), 3, Options{}, true},
+		{"different values", json.Number("2"), float64(3), Options{}, false},
+		{"epsilon with json.Number", json.Number("1.0000001"), float64(1), Options{Epsilon: 1e-5}, true},
+		{"number and numeric string", json.Number("1"), "1", Options{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+
+	subset := map[string]interface{}{"ids": []interface{}{json.Number("1"), 2}}
+	superset := map[string]interface{}{"ids": []interface{}{float64(2), float64(1)}}
+	if ok, diffs := CheckSubset(subset, superset); !ok {
+		t.Errorf("mixed numeric arrays should match, diffs: %+v", diffs)
+	}
+}
+
+func TestLargeIntegers(t *testing.T) {
+	// Both round to the same float64.
+	const id, nextID = "1234567890123456789", "1234567890123456788"
+	if float64(1234567890123456789) != float64(1234567890123456788) {
+		t.Fatal("test values should collide as float64")
+	}
+
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		opts       Options
+		wantSubset bool
+	}{
+		{"float64 loses the difference", float64(1234567890123456789), float64(1234567890123456788), Options{}, true},
+		{"json.Number keeps it", json.Number(id), json.Number(nextID), Options{}, false},
+		{"json.Number equal", json.Number(id), json.Number(id), Options{}, true},
+		{"json.Number and int64", json.Number(id), int64(1234567890123456789), Options{}, true},
+		{"json.Number and uint64", json.Number("18446744073709551615"), uint64(18446744073709551615), Options{}, true},
+		{"exponent form", json.Number("1.5e2"), json.Number("150"), Options{}, true},
+		{"in a set array", []interface{}{json.Number(id)}, []interface{}{json.Number(nextID), json.Number("7")}, Options{}, false},
+		{"in a set array, present", []interface{}{json.Number(id)}, []interface{}{json.Number(nextID), json.Number(id)}, Options{}, true},
+		{"epsilon compares as float64", json.Number(id), json.Number(nextID), Options{Epsilon: 1}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+}
+
+func TestNormalizeNumbers(t *testing.T) {
+	normalize := Options{NormalizeNumbers: true}
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		opts       Options
+		wantSubset bool
+	}{
+		{"trailing zero", json.Number("1.10"), json.Number("1.1"), normalize, true},
+		{"different decimals", json.Number("1.11"), json.Number("1.1"), normalize, false},
+		{"integer and decimal", json.Number("1"), json.Number("1.0"), normalize, true},
+		{"exponent form", json.Number("1.5e2"), json.Number("150"), normalize, true},
+		{"float at its decimal spelling", json.Number("0.1"), float64(0.1), normalize, true},
+		{"float rounding tolerated by default", json.Number("0.30000000000000001"), float64(0.3), Options{}, true},
+		{"float rounding rejected", json.Number("0.30000000000000001"), float64(0.3), normalize, false},
+		{"int and json.Number", 7, json.Number("7.00"), normalize, true},
+		{"epsilon takes precedence", json.Number("1.11"), json.Number("1.1"), Options{NormalizeNumbers: true, Epsilon: 0.1}, true},
+		{"number and numeric string", json.Number("1"), "1", normalize, false},
+		{"in a set array", []interface{}{json.Number("0.30000000000000001")}, []interface{}{float64(0.3)}, normalize, false},
+		{"in a set array, present", []interface{}{json.Number("2.50")}, []interface{}{float64(1), float64(2.5)}, normalize, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+}
+
+func TestStrictTypes(t *testing.T) {
+	strict := Options{StrictTypes: true}
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		opts       Options
+		wantSubset bool
+	}{
+		{"same json.Number", json.Number("1.5"), json.Number("1.5"), strict, true},
+		{"json.Number spellings", json.Number("1.0"), json.Number("1"), strict, false},
+		{"json.Number and float64", json.Number("30"), float64(30), strict, true},
+		{"json.Number and float64 spellings", json.Number("30.0"), float64(30), strict, false},
+		{"int and float64", 42, float64(42), strict, true},
+		{"int and json.Number spellings", 42, json.Number("4.2e1"), strict, false},
+		{"epsilon overridden", json.Number("1.0000001"), json.Number("1"), Options{StrictTypes: true, Epsilon: 1e-5}, false},
+		{"normalize overridden", json.Number("1.10"), json.Number("1.1"), Options{StrictTypes: true, NormalizeNumbers: true}, false},
+		{"coerce-bool overridden", "true", true, Options{StrictTypes: true, CoerceBool: true}, false},
+		{"empty-equals-null overridden", "", nil, Options{StrictTypes: true, EmptyEqualsNull: true}, false},
+		{"ignore-case still applies", "Alice", "alice", Options{StrictTypes: true, IgnoreCase: true}, true},
+		{"in a set array", []interface{}{json.Number("1.0")}, []interface{}{json.Number("1")}, strict, false},
+		{"in a set array, present", []interface{}{json.Number("1")}, []interface{}{json.Number("2"), json.Number("1")}, strict, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+
+	rule, err := ParseRule("$.price", "epsilon:0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rules := Options{StrictTypes: true, Rules: []Rule{rule}}
+	subset := map[string]interface{}{"price": json.Number("9.99")}
+	superset := map[string]interface{}{"price": json.Number("10")}
+	if ok, _ := CheckSubsetWithOptions(subset, superset, rules); ok {
+		t.Error("an epsilon rule should not loosen StrictTypes")
+	}
+
+	_, diffs := CheckSubsetWithOptions(json.Number("1.0"), float64(1), strict)
+	if len(diffs) != 1 || diffs[0].Message != "strict types: subset is spelled 1.0, superset is spelled 1" {
+		t.Errorf("diffs = %+v, want a message naming both spellings", diffs)
+	}
+}
+
+func TestHugeExponents(t *testing.T) {
+	tests := []struct {
+		name     string
+		subset   interface{}
+		superset interface{}
+		opts     Options
+		want     bool
+	}{
+		{"same literal", json.Number("1e888880"), json.Number("1e888880"), Options{}, true},
+		{"tiny numbers round to zero", json.Number("1e-888880"), json.Number("0"), Options{}, true},
+		{"tiny numbers normalized", json.Number("2e-888880"), json.Number("1e-888881"), Options{NormalizeNumbers: true}, true},
+		{"huge and small", json.Number("1e888880"), json.Number("1"), Options{}, false},
+		{"in range stays exact", json.Number("1e-400"), json.Number("0"), Options{}, false},
+		{"in array", []interface{}{json.Number("1e-888880")}, []interface{}{json.Number("5"), json.Number("0")}, Options{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts); got != tt.want {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package subset
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// toObject returns v as a JSON object. Library callers may pass Go maps
+// with other key or value types, such as map[int]interface{}; they are
+// copied with each key formatted by fmt.Sprint, which also names it in
+// diff paths. Only the top level is copied: nested maps are converted when
+// the comparison reaches them. Keys that format alike, like 1 and "1" in
+// a map[interface{}]interface{}, collide and only one of them is kept.
+func toObject(v interface{}) (map[string]interface{}, bool) {
+	if m, ok := v.(map[string]interface{}); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map {
+		return nil, false
+	}
+	m := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		m[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+	}
+	return m, true
+}
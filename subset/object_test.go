@@ -0,0 +1,92 @@
+package subset
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNonStringMapKeys(t *testing.T) {
+	tests := []struct {
+		name      string
+		subset    interface{}
+		superset  interface{}
+		opts      Options
+		want      bool
+		wantPaths []string
+	}{
+		{
+			name:     "int keys",
+			subset:   map[int]interface{}{1: "a"},
+			superset: map[int]interface{}{1: "a", 2: "b"},
+			want:     true,
+		},
+		{
+			name:      "int keys mismatch",
+			subset:    map[int]interface{}{1: "a", 3: "c"},
+			superset:  map[int]interface{}{1: "x"},
+			wantPaths: []string{"$['1']", "$['3']"},
+		},
+		{
+			name:     "against a JSON object",
+			subset:   map[int]interface{}{7: float64(1)},
+			superset: map[string]interface{}{"7": float64(1)},
+			want:     true,
+		},
+		{
+			name:      "nested interface keys",
+			subset:    map[string]interface{}{"m": map[interface{}]interface{}{true: []interface{}{1}}},
+			superset:  map[string]interface{}{"m": map[interface{}]interface{}{true: []interface{}{2}}},
+			wantPaths: []string{"$['m']['true'][0]"},
+		},
+		{
+			name:     "typed values",
+			subset:   map[int]string{1: "a"},
+			superset: map[int]interface{}{1: "a", 2: nil},
+			want:     true,
+		},
+		{
+			name:     "keyed array elements",
+			subset:   []interface{}{map[int]interface{}{0: "x", 1: "b"}},
+			superset: []interface{}{map[string]interface{}{"0": "y"}, map[string]interface{}{"0": "x", "1": "b"}},
+			opts:     Options{ArrayKey: "0"},
+			want:     true,
+		},
+		{
+			name:      "not a map",
+			subset:    map[int]interface{}{1: "a"},
+			superset:  []interface{}{"a"},
+			wantPaths: []string{"$"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.want {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v; diffs: %+v", got, tt.want, diffs)
+			}
+			var paths []string
+			for _, d := range diffs {
+				paths = append(paths, d.Path.String())
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) {
+				t.Errorf("diff paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+
+	// The text output renders such maps like JSON objects.
+	subset := map[int]interface{}{1: "a"}
+	_, diffs := CheckSubset(subset, map[int]interface{}{1: "b"})
+	if output := FormatDiffOutput(subset, diffs); !strings.Contains(output, `-  "1": "a" (superset: "b")`) {
+		t.Errorf("FormatDiffOutput() =\n%s", output)
+	}
+
+	// A map that contains itself is caught like a cyclic JSON object.
+	cyclic := map[int]interface{}{}
+	cyclic[0] = cyclic
+	if ok, _ := CheckSubset(cyclic, cyclic); ok {
+		t.Error("CheckSubset() of a cyclic map should fail")
+	}
+}
@@ -0,0 +1,53 @@
+package subset
+
+import "github.com/theory/jsonpath/spec"
+
+// ignoreUnselected returns ignored extended with every subset location that
+// is neither selected by only, nor inside or on the way to a selected
+// location, so that only the selected parts of the subset are compared
+func ignoreUnselected(ignored, only *pathSet, doc interface{}) *pathSet {
+	result := &pathSet{paths: make(map[string]bool)}
+	if ignored != nil {
+		for p := range ignored.paths {
+			result.paths[p] = true
+		}
+		result.globs = ignored.globs
+	}
+	markUnselected(doc, spec.NormalizedPath{}, only, result)
+	return result
+}
+
+// markUnselected reports whether anything at or below path is selected. If
+// so, or at the root, the children with nothing selected are added to result.
+func markUnselected(v interface{}, path spec.NormalizedPath, only, result *pathSet) bool {
+	if only.paths[path.String()] || only.contains(path) {
+		return true
+	}
+
+	var unselected []spec.NormalizedPath
+	selectedBelow := false
+	visit := func(child interface{}, childPath spec.NormalizedPath) {
+		if markUnselected(child, childPath, only, result) {
+			selectedBelow = true
+		} else {
+			unselected = append(unselected, childPath)
+		}
+	}
+	switch c := v.(type) {
+	case map[string]interface{}:
+		for key, child := range c {
+			visit(child, append(copyPath(path), spec.Name(key)))
+		}
+	case []interface{}:
+		for i, child := range c {
+			visit(child, append(copyPath(path), spec.Index(i)))
+		}
+	}
+
+	if selectedBelow || len(path) == 0 {
+		for _, p := range unselected {
+			result.paths[p.String()] = true
+		}
+	}
+	return selectedBelow
+}
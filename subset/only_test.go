@@ -0,0 +1,59 @@
+package subset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestOnly(t *testing.T) {
+	subset := map[string]interface{}{
+		"id":     float64(1),
+		"name":   "alice",
+		"status": "active",
+		"user":   map[string]interface{}{"email": "a@example.com", "role": "admin"},
+		"items":  []interface{}{map[string]interface{}{"sku": "a", "qty": float64(1)}},
+	}
+	superset := map[string]interface{}{
+		"id":     float64(1),
+		"name":   "bob",
+		"status": "active",
+		"user":   map[string]interface{}{"email": "b@example.com", "role": "admin"},
+		"items":  []interface{}{map[string]interface{}{"sku": "a", "qty": float64(2)}},
+	}
+
+	tests := []struct {
+		name      string
+		only      []string
+		wantPaths []string
+	}{
+		{"no restriction", nil, []string{"$['items'][0]", "$['name']", "$['user']['email']"}},
+		{"matching paths only", []string{"$.id", "$.status"}, nil},
+		{"a failing path is still checked", []string{"$.id", "$.name"}, []string{"$['name']"}},
+		{"nested path", []string{"$.user.role"}, nil},
+		{"whole object", []string{"$.user"}, []string{"$['user']['email']"}},
+		{"inside array elements", []string{"$.items[*].sku"}, nil},
+		{"key glob", []string{"role"}, nil},
+		{"key glob with a failure", []string{"email", "status"}, []string{"$['user']['email']"}},
+		{"nothing selected", []string{"$.missing"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Only: mustPatterns(t, tt.only...)}
+			ok, diffs := CheckSubsetWithOptions(subset, superset, opts)
+			var paths []string
+			for _, d := range diffs {
+				paths = append(paths, d.Path.String())
+			}
+			if !reflect.DeepEqual(paths, tt.wantPaths) || ok != (len(tt.wantPaths) == 0) {
+				t.Errorf("CheckSubsetWithOptions() = %v with diff paths %v, want %v", ok, paths, tt.wantPaths)
+			}
+		})
+	}
+
+	// Ignore still applies inside the selected locations.
+	opts := Options{Only: mustPatterns(t, "$.user"), Ignore: mustPatterns(t, "email")}
+	if ok, diffs := CheckSubsetWithOptions(subset, superset, opts); !ok {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+}
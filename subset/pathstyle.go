@@ -0,0 +1,58 @@
+package subset
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// PathStyle selects how diff paths are rendered
+type PathStyle int
+
+const (
+	// PathJSONPath renders normalized JSONPath such as $['a'][0]
+	PathJSONPath PathStyle = iota
+	// PathPointer renders an RFC 6901 JSON Pointer such as /a/0
+	PathPointer
+	// PathDotted renders keys and indices joined by dots such as a.0, with
+	// dots and backslashes in keys escaped by a backslash
+	PathDotted
+)
+
+// ParsePathStyle converts a name such as "pointer" into a PathStyle
+func ParsePathStyle(s string) (PathStyle, error) {
+	switch s {
+	case "jsonpath":
+		return PathJSONPath, nil
+	case "pointer":
+		return PathPointer, nil
+	case "dotted":
+		return PathDotted, nil
+	default:
+		return PathJSONPath, fmt.Errorf("invalid path style %q (want jsonpath, pointer or dotted)", s)
+	}
+}
+
+// FormatPath renders path in the given style. The root is "$" as JSONPath
+// and the empty string otherwise.
+func FormatPath(path spec.NormalizedPath, style PathStyle) string {
+	switch style {
+	case PathPointer:
+		return path.Pointer()
+	case PathDotted:
+		parts := make([]string, 0, len(path))
+		for _, sel := range path {
+			switch sel := sel.(type) {
+			case spec.Name:
+				parts = append(parts, strings.NewReplacer(`\`, `\\`, ".", `\.`).Replace(string(sel)))
+			case spec.Index:
+				parts = append(parts, strconv.Itoa(int(sel)))
+			}
+		}
+		return strings.Join(parts, ".")
+	default:
+		return path.String()
+	}
+}
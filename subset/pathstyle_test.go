@@ -0,0 +1,71 @@
+package subset
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+func TestFormatPath(t *testing.T) {
+	nested := spec.NormalizedPath{spec.Name("items"), spec.Index(0), spec.Name("tags"), spec.Index(12)}
+	tests := []struct {
+		name  string
+		path  spec.NormalizedPath
+		style PathStyle
+		want  string
+	}{
+		{"jsonpath", nested, PathJSONPath, "$['items'][0]['tags'][12]"},
+		{"pointer", nested, PathPointer, "/items/0/tags/12"},
+		{"dotted", nested, PathDotted, "items.0.tags.12"},
+		{"jsonpath root", nil, PathJSONPath, "$"},
+		{"pointer root", nil, PathPointer, ""},
+		{"dotted root", nil, PathDotted, ""},
+		{"pointer escapes", spec.NormalizedPath{spec.Name("a/b"), spec.Name("c~d")}, PathPointer, "/a~1b/c~0d"},
+		{"dotted escapes", spec.NormalizedPath{spec.Name("example.com"), spec.Name(`a\b`)}, PathDotted, `example\.com.a\\b`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatPath(tt.path, tt.style); got != tt.want {
+				t.Errorf("FormatPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePathStyle(t *testing.T) {
+	for name, want := range map[string]PathStyle{"jsonpath": PathJSONPath, "pointer": PathPointer, "dotted": PathDotted} {
+		got, err := ParsePathStyle(name)
+		if err != nil || got != want {
+			t.Errorf("ParsePathStyle(%q) = %v, %v; want %v", name, got, err, want)
+		}
+	}
+	if _, err := ParsePathStyle("slash"); err == nil {
+		t.Error("ParsePathStyle(\"slash\") succeeded, want an error")
+	}
+}
+
+func TestPathStyleInFormats(t *testing.T) {
+	sub := map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": "a"}}}
+	super := map[string]interface{}{"items": []interface{}{map[string]interface{}{"id": "b"}}}
+	_, diffs := CheckSubsetWithOptions(sub, super, Options{ArrayOrder: ArrayOrdered})
+	opts := FormatOptions{PathStyle: PathPointer}
+
+	jsonOutput, err := FormatDiffJSONWithOptions(diffs, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := map[string]string{
+		"values only": FormatDiffOutputWithOptions(sub, diffs, FormatOptions{PathStyle: PathPointer, ValuesOnly: true}),
+		"json":        jsonOutput,
+		"table":       FormatDiffTable(diffs, opts),
+		"github":      FormatDiffGitHubWithOptions(diffs, "", opts),
+		"tap":         FormatDiffTAPWithOptions(false, "check", diffs, opts),
+	}
+	for name, out := range outputs {
+		if !strings.Contains(out, "/items/0/id") || strings.Contains(out, "$[") {
+			t.Errorf("%s output does not use pointer paths:\n%s", name, out)
+		}
+	}
+}
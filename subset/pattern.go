@@ -0,0 +1,109 @@
+package subset
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/theory/jsonpath"
+	"github.com/theory/jsonpath/spec"
+)
+
+// PathPattern selects locations in the subset document. A pattern starting
+// with "$" is a JSONPath expression; anything else is a glob matched against
+// object key names at any depth.
+type PathPattern struct {
+	raw   string
+	query *jsonpath.Path
+}
+
+// ParsePathPattern parses a JSONPath expression or key glob
+func ParsePathPattern(s string) (PathPattern, error) {
+	if strings.HasPrefix(s, "$") {
+		query, err := jsonpath.Parse(s)
+		if err != nil {
+			return PathPattern{}, err
+		}
+		return PathPattern{raw: s, query: query}, nil
+	}
+
+	if _, err := path.Match(s, ""); err != nil {
+		return PathPattern{}, err
+	}
+	return PathPattern{raw: s}, nil
+}
+
+// String returns the pattern as it was written
+func (p PathPattern) String() string {
+	return p.raw
+}
+
+// pathSet holds the subset locations selected by a list of patterns
+type pathSet struct {
+	paths map[string]bool
+	globs []string
+}
+
+// newPathSet resolves the JSONPath patterns against the subset document
+func newPathSet(patterns []PathPattern, doc interface{}) *pathSet {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	set := &pathSet{paths: make(map[string]bool)}
+	for _, p := range patterns {
+		if p.query == nil {
+			set.globs = append(set.globs, p.raw)
+			continue
+		}
+		for located := range p.query.SelectLocated(doc).Paths() {
+			set.paths[located.String()] = true
+		}
+	}
+	return set
+}
+
+// contains reports whether the location is selected by any pattern
+func (s *pathSet) contains(p spec.NormalizedPath) bool {
+	if s == nil || len(p) == 0 {
+		return false
+	}
+	if s.paths[p.String()] {
+		return true
+	}
+
+	name, ok := p[len(p)-1].(spec.Name)
+	if !ok {
+		return false
+	}
+	for _, glob := range s.globs {
+		if matched, _ := path.Match(glob, string(name)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectNode returns the single node a JSONPath expression selects from doc
+func SelectNode(doc interface{}, expr string) (interface{}, error) {
+	node, _, err := LocateNode(doc, expr)
+	return node, err
+}
+
+// LocateNode is like SelectNode but also returns the node's location in doc
+func LocateNode(doc interface{}, expr string) (interface{}, spec.NormalizedPath, error) {
+	query, err := jsonpath.Parse(expr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nodes := query.SelectLocated(doc)
+	switch len(nodes) {
+	case 0:
+		return nil, nil, fmt.Errorf("path %s matches nothing", expr)
+	case 1:
+		return nodes[0].Node, nodes[0].Path, nil
+	default:
+		return nil, nil, fmt.Errorf("path %s matches %d nodes, want exactly one", expr, len(nodes))
+	}
+}
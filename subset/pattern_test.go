@@ -0,0 +1,112 @@
+package subset
+
+import "testing"
+
+func mustPatterns(t *testing.T, patterns ...string) []PathPattern {
+	t.Helper()
+	var result []PathPattern
+	for _, s := range patterns {
+		p, err := ParsePathPattern(s)
+		if err != nil {
+			t.Fatalf("ParsePathPattern(%q) error = %v", s, err)
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+func TestIgnore(t *testing.T) {
+	subset := map[string]interface{}{
+		"name":      "alice",
+		"timestamp": "2020-01-01T00:00:00Z",
+		"meta": map[string]interface{}{
+			"requestId": "abc",
+			"region":    "us",
+		},
+		"events": []interface{}{
+			map[string]interface{}{"type": "login", "timestamp": "2020-01-01T00:00:00Z"},
+		},
+	}
+	superset := map[string]interface{}{
+		"name":      "alice",
+		"timestamp": "2021-06-01T12:00:00Z",
+		"meta": map[string]interface{}{
+			"region": "us",
+		},
+		"events": []interface{}{
+			map[string]interface{}{"type": "login", "timestamp": "2021-06-01T12:00:00Z"},
+		},
+	}
+
+	if ok, _ := CheckSubset(subset, superset); ok {
+		t.Fatal("CheckSubset() = true without ignore patterns, want false")
+	}
+
+	tests := []struct {
+		name       string
+		patterns   []string
+		wantSubset bool
+	}{
+		{
+			name:       "leaf key only",
+			patterns:   []string{"timestamp"},
+			wantSubset: false,
+		},
+		{
+			name:       "full path only",
+			patterns:   []string{"$.meta.requestId"},
+			wantSubset: false,
+		},
+		{
+			name:       "leaf key and full path",
+			patterns:   []string{"timestamp", "$.meta.requestId"},
+			wantSubset: true,
+		},
+		{
+			name:       "glob and wildcard path",
+			patterns:   []string{"time*", "$.meta.*"},
+			wantSubset: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := Options{Ignore: mustPatterns(t, tt.patterns...)}
+			got, diffs := CheckSubsetWithOptions(subset, superset, opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v (diffs: %+v)", got, tt.wantSubset, diffs)
+			}
+		})
+	}
+}
+
+func TestParsePathPatternInvalid(t *testing.T) {
+	for _, s := range []string{"$.[", "[a-"} {
+		if _, err := ParsePathPattern(s); err == nil {
+			t.Errorf("ParsePathPattern(%q) should fail", s)
+		}
+	}
+}
+
+func TestSelectNode(t *testing.T) {
+	doc := map[string]interface{}{
+		"data": map[string]interface{}{
+			"user":  map[string]interface{}{"name": "alice", "age": float64(30)},
+			"items": []interface{}{float64(1), float64(2)},
+		},
+	}
+
+	node, err := SelectNode(doc, "$.data.user")
+	if err != nil {
+		t.Fatalf("SelectNode() error = %v", err)
+	}
+	if ok, diffs := CheckSubset(map[string]interface{}{"name": "alice"}, node); !ok {
+		t.Errorf("subset should match the selected user, diffs: %+v", diffs)
+	}
+
+	for _, expr := range []string{"$.data.missing", "$.data.items[*]", "$.["} {
+		if _, err := SelectNode(doc, expr); err == nil {
+			t.Errorf("SelectNode(%q) should fail", expr)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package subset
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultProgressInterval is how often Options.Progress is called when
+// ProgressInterval is 0
+const DefaultProgressInterval = 250 * time.Millisecond
+
+// progressCheckEvery is how many values are compared between looks at
+// the clock, so that reporting stays cheap on large documents
+const progressCheckEvery = 256
+
+// progressTracker counts compared values across goroutines and calls the
+// Progress callback when the interval has passed. A nil tracker does nothing.
+type progressTracker struct {
+	report   func(values int)
+	interval time.Duration
+	values   atomic.Int64
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+func newProgressTracker(opts Options) *progressTracker {
+	if opts.Progress == nil {
+		return nil
+	}
+	interval := opts.ProgressInterval
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	return &progressTracker{report: opts.Progress, interval: interval, last: time.Now()}
+}
+
+// add counts n more compared values, the same ones Stats counts
+func (p *progressTracker) add(n int) {
+	if p == nil || n == 0 {
+		return
+	}
+	total := p.values.Add(int64(n))
+	if total/progressCheckEvery == (total-int64(n))/progressCheckEvery {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if now := time.Now(); now.Sub(p.last) >= p.interval {
+		p.last = now
+		p.report(int(p.values.Load()))
+	}
+}
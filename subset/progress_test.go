@@ -0,0 +1,58 @@
+package subset
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestProgress(t *testing.T) {
+	doc := make(map[string]interface{})
+	for i := 0; i < 100; i++ {
+		items := make([]interface{}, 20)
+		for j := range items {
+			items[j] = map[string]interface{}{"n": float64(j)}
+		}
+		doc[fmt.Sprintf("key%d", i)] = items
+	}
+
+	var stats Stats
+	CheckSubsetWithOptions(doc, doc, Options{Stats: &stats, ArrayOrder: ArrayOrdered})
+	total := stats.ObjectKeys + stats.ArrayElements + stats.Primitives
+
+	for _, parallel := range []int{1, 4} {
+		var mu sync.Mutex
+		var calls []int
+		opts := Options{
+			ArrayOrder:       ArrayOrdered,
+			Parallel:         parallel,
+			ProgressInterval: time.Nanosecond,
+			Progress: func(values int) {
+				mu.Lock()
+				defer mu.Unlock()
+				calls = append(calls, values)
+			},
+		}
+		if ok, diffs := CheckSubsetWithOptions(doc, doc, opts); !ok {
+			t.Fatalf("unexpected diffs: %+v", diffs)
+		}
+		if len(calls) == 0 {
+			t.Fatalf("parallel %d: Progress was never called", parallel)
+		}
+		for i, values := range calls {
+			if values <= 0 || values > total || (i > 0 && values < calls[i-1]) {
+				t.Errorf("parallel %d: Progress calls = %v, want increasing counts up to %d", parallel, calls, total)
+				break
+			}
+		}
+	}
+
+	// Updates are throttled to the interval.
+	calls := 0
+	opts := Options{ArrayOrder: ArrayOrdered, ProgressInterval: time.Hour, Progress: func(int) { calls++ }}
+	CheckSubsetWithOptions(doc, doc, opts)
+	if calls != 0 {
+		t.Errorf("Progress called %d times within the interval, want 0", calls)
+	}
+}
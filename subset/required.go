@@ -0,0 +1,104 @@
+package subset
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// requiredKeyDiffs walks the superset and reports a DiffMissingKey for every
+// object lacking one of opts.RequiredKeys. The diff path points into the
+// superset, at the place where the key should be. opts.ignored must have
+// been resolved against the superset, as by requiredOptions.
+func requiredKeyDiffs(superset interface{}, path spec.NormalizedPath, opts Options) []Diff {
+	var diffs []Diff
+	if first, ok := enterContainer(superset, path, &opts); !ok {
+		return []Diff{{
+			Path:    copyPath(path),
+			Type:    DiffValueMismatch,
+			Message: fmt.Sprintf("cyclic structure: the superset value contains itself, first visited at %s", first),
+		}}
+	}
+
+	if v, ok := toObject(superset); ok {
+		for _, key := range opts.RequiredKeys {
+			childPath := append(copyPath(path), spec.Name(key))
+			if opts.ignored.contains(childPath) {
+				continue
+			}
+			if _, exists := lookupKey(v, key, opts); !exists {
+				diffs = append(diffs, Diff{
+					Path:    childPath,
+					Type:    DiffMissingKey,
+					Message: fmt.Sprintf("required key %q is missing", key),
+				})
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			childPath := append(copyPath(path), spec.Name(k))
+			if !opts.ignored.contains(childPath) {
+				diffs = append(diffs, requiredKeyDiffs(v[k], childPath, opts)...)
+			}
+		}
+		return diffs
+	}
+
+	if v, ok := superset.([]interface{}); ok {
+		for i, elem := range v {
+			childPath := append(copyPath(path), spec.Index(i))
+			if !opts.ignored.contains(childPath) {
+				diffs = append(diffs, requiredKeyDiffs(elem, childPath, opts)...)
+			}
+		}
+	}
+	return diffs
+}
+
+// requiredOptions resolves opts.Ignore against the superset for
+// requiredKeyDiffs. A JSONPath cannot select a key that is missing, so the
+// patterns are resolved against a copy in which every missing required key
+// is present as null; "$.meta.requestId" then covers a requestId that
+// $.meta lacks. Only patterns select subset locations and do not apply.
+func requiredOptions(superset interface{}, opts Options) Options {
+	opts.visiting = nil
+	opts.ignored = nil
+	if len(opts.Ignore) > 0 {
+		opts.ignored = newPathSet(opts.Ignore, withRequiredKeys(superset, spec.NormalizedPath{}, opts))
+	}
+	return opts
+}
+
+// withRequiredKeys copies v with every missing required key set to null.
+// A container that encloses itself is cut off where it repeats.
+func withRequiredKeys(v interface{}, path spec.NormalizedPath, opts Options) interface{} {
+	if _, ok := enterContainer(v, path, &opts); !ok {
+		return nil
+	}
+	if obj, ok := toObject(v); ok {
+		filled := make(map[string]interface{}, len(obj)+len(opts.RequiredKeys))
+		for k, elem := range obj {
+			filled[k] = withRequiredKeys(elem, append(copyPath(path), spec.Name(k)), opts)
+		}
+		for _, key := range opts.RequiredKeys {
+			if _, exists := lookupKey(obj, key, opts); !exists {
+				filled[key] = nil
+			}
+		}
+		return filled
+	}
+	if arr, ok := v.([]interface{}); ok {
+		filled := make([]interface{}, len(arr))
+		for i, elem := range arr {
+			filled[i] = withRequiredKeys(elem, append(copyPath(path), spec.Index(i)), opts)
+		}
+		return filled
+	}
+	return v
+}
@@ -0,0 +1,128 @@
+package subset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRequiredKeys(t *testing.T) {
+	subset := map[string]interface{}{"name": "app"}
+	superset := map[string]interface{}{
+		"id":   float64(1),
+		"name": "app",
+		"items": []interface{}{
+			map[string]interface{}{"id": float64(2)},
+			map[string]interface{}{"value": "x"},
+		},
+		"owner": map[string]interface{}{"id": float64(3), "meta": map[string]interface{}{}},
+	}
+
+	ok, diffs := CheckSubsetWithOptions(subset, superset, Options{RequiredKeys: []string{"id"}})
+	if ok {
+		t.Fatal("objects missing a required key should fail the check")
+	}
+	want := []string{"$['items'][1]['id']", "$['owner']['meta']['id']"}
+	if len(diffs) != len(want) {
+		t.Fatalf("got %d diffs, want %d: %+v", len(diffs), len(want), diffs)
+	}
+	for i, path := range want {
+		if diffs[i].Type != DiffMissingKey || diffs[i].Path.String() != path {
+			t.Errorf("diff %d = %v %s, want missing key %s", i, diffs[i].Type, diffs[i].Path, path)
+		}
+		if diffs[i].Message != `required key "id" is missing` {
+			t.Errorf("diff %d message = %q", i, diffs[i].Message)
+		}
+	}
+
+	if ok, diffs := CheckSubset(subset, superset); !ok {
+		t.Errorf("without RequiredKeys the check should pass, got %+v", diffs)
+	}
+}
+
+func TestRequiredKeysIgnore(t *testing.T) {
+	subset := map[string]interface{}{"name": "app"}
+	superset := map[string]interface{}{
+		"name":  "app",
+		"items": []interface{}{map[string]interface{}{"value": "x"}, map[string]interface{}{"value": "y"}},
+		"meta":  map[string]interface{}{"labels": map[string]interface{}{}},
+		"owner": map[string]interface{}{"name": "bob"},
+	}
+
+	tests := []struct {
+		name   string
+		ignore []string
+		want   []string
+	}{
+		{"nothing ignored", nil, []string{"$['id']", "$['items'][0]['id']", "$['items'][1]['id']", "$['meta']['id']", "$['meta']['labels']['id']", "$['owner']['id']"}},
+		// The subset has no items, so resolving against it would select nothing.
+		{"missing key by JSONPath", []string{"$.items[0].id", "$.owner.id"}, []string{"$['id']", "$['items'][1]['id']", "$['meta']['id']", "$['meta']['labels']['id']"}},
+		{"wildcard JSONPath", []string{"$.items[*].id"}, []string{"$['id']", "$['meta']['id']", "$['meta']['labels']['id']", "$['owner']['id']"}},
+		{"ignored object skipped", []string{"$.meta"}, []string{"$['id']", "$['items'][0]['id']", "$['items'][1]['id']", "$['owner']['id']"}},
+		{"key glob", []string{"id"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patterns []PathPattern
+			for _, raw := range tt.ignore {
+				p, err := ParsePathPattern(raw)
+				if err != nil {
+					t.Fatal(err)
+				}
+				patterns = append(patterns, p)
+			}
+			_, diffs := CheckSubsetWithOptions(subset, superset, Options{RequiredKeys: []string{"id"}, Ignore: patterns})
+			var got []string
+			for _, d := range diffs {
+				got = append(got, d.Path.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diff paths = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequiredKeysOtherMapTypes(t *testing.T) {
+	superset := map[string]interface{}{
+		"id":    float64(1),
+		"users": []interface{}{map[string]string{"name": "alice"}, map[interface{}]interface{}{"id": 2}},
+	}
+	ok, diffs := CheckSubsetWithOptions(map[string]interface{}{}, superset, Options{RequiredKeys: []string{"id"}})
+	if ok || len(diffs) != 1 || diffs[0].Path.String() != "$['users'][0]['id']" {
+		t.Errorf("CheckSubsetWithOptions() = %v, %+v, want one missing id at $['users'][0]", ok, diffs)
+	}
+}
+
+func TestRequiredKeysWithOtherDiffs(t *testing.T) {
+	subset := map[string]interface{}{"name": "other"}
+	superset := map[string]interface{}{"name": "app"}
+
+	ok, diffs := CheckSubsetWithOptions(subset, superset, Options{RequiredKeys: []string{"id", "version"}})
+	if ok || len(diffs) != 3 {
+		t.Fatalf("CheckSubsetWithOptions() = %v with %d diffs, want false with 3", ok, len(diffs))
+	}
+	if diffs[0].Type != DiffValueMismatch {
+		t.Errorf("subset diffs should come first, got %v", diffs[0].Type)
+	}
+}
+
+func TestFormatRequiredKeyDiffs(t *testing.T) {
+	subset := map[string]interface{}{"name": "app"}
+	superset := map[string]interface{}{
+		"id":    float64(1),
+		"name":  "app",
+		"owner": map[string]interface{}{"name": "alice"},
+	}
+
+	_, diffs := CheckSubsetWithOptions(subset, superset, Options{RequiredKeys: []string{"id"}})
+	got := FormatDiffOutput(subset, diffs)
+	want := ` {
+   "name": "app"
+ }
+- $['owner']['id']: required key "id" is missing
+`
+	if got != want {
+		t.Errorf("FormatDiffOutput() =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+package subset
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// Rule overrides the comparison options for the subset locations its
+// pattern selects, including everything nested below them
+type Rule struct {
+	Pattern   PathPattern
+	Directive string
+	apply     func(*Options)
+}
+
+// ParseRule parses a comparison directive for the locations selected by
+// pattern. The directives are:
+//
+//	exact         compare values exactly, undoing any global tolerance
+//	epsilon:N     treat numbers within N of each other as equal
+//	regex         treat "re:/pattern/" strings as regular expressions
+//	ignore-case   compare strings case-insensitively
+//	type          only require the same JSON type
+//	key:NAME      pair array elements by their NAME key (see Options.ArrayKey)
+//	set           compare arrays as sets, ignoring order
+//	ordered       compare array elements at the same index
+//	multiset      compare arrays ignoring order but counting duplicates
+func ParseRule(pattern, directive string) (Rule, error) {
+	p, err := ParsePathPattern(pattern)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	rule := Rule{Pattern: p, Directive: directive}
+	name, arg, hasArg := strings.Cut(directive, ":")
+	switch {
+	case name == "exact" && !hasArg:
+		rule.apply = exactOptions
+	case name == "epsilon" && hasArg:
+		epsilon, err := strconv.ParseFloat(arg, 64)
+		if err != nil || epsilon < 0 {
+			return Rule{}, fmt.Errorf("invalid epsilon %q", arg)
+		}
+		rule.apply = func(o *Options) { o.Epsilon = epsilon }
+	case name == "regex" && !hasArg:
+		rule.apply = func(o *Options) { o.EnableRegex = true }
+	case name == "ignore-case" && !hasArg:
+		rule.apply = func(o *Options) { o.IgnoreCase = true }
+	case name == "type" && !hasArg:
+		rule.apply = func(o *Options) { o.IgnoreValues = true }
+	case name == "key" && arg != "":
+		rule.apply = func(o *Options) { o.ArrayKey = arg }
+	case (name == "set" || name == "ordered" || name == "multiset") && !hasArg:
+		order := map[string]ArrayOrder{"set": ArraySet, "ordered": ArrayOrdered, "multiset": ArrayMultiset}[name]
+		// A key would take precedence over the order.
+		rule.apply = func(o *Options) { o.ArrayOrder, o.ArrayKey = order, "" }
+	default:
+		return Rule{}, fmt.Errorf("unknown directive %q (want exact, epsilon:N, regex, ignore-case, type, key:NAME, set, ordered or multiset)", directive)
+	}
+	return rule, nil
+}
+
+// exactOptions switches off every option that lets different values compare
+// equal
+func exactOptions(o *Options) {
+	o.Epsilon = 0
+	o.NormalizeNumbers = false
+	o.IgnoreCase = false
+	o.TrimStrings = false
+	o.CoerceBool = false
+	o.EmptyEqualsNull = false
+	o.EnableRegex = false
+	o.EnableMatchers = false
+	o.IgnoreValues = false
+	o.AllowTypeWidening = false
+}
+
+// ParseRules parses a map of patterns to directives. The rules are sorted
+// by pattern; where several select the same location they apply in that order.
+func ParseRules(directives map[string]string) ([]Rule, error) {
+	patterns := make([]string, 0, len(directives))
+	for p := range directives {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+
+	rules := make([]Rule, 0, len(patterns))
+	for _, p := range patterns {
+		rule, err := ParseRule(p, directives[p])
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", p, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// resolvedRule is a Rule with its pattern resolved against the subset
+type resolvedRule struct {
+	paths *pathSet
+	apply func(*Options)
+}
+
+func resolveRules(rules []Rule, doc interface{}) []resolvedRule {
+	resolved := make([]resolvedRule, 0, len(rules))
+	for _, r := range rules {
+		resolved = append(resolved, resolvedRule{paths: newPathSet([]PathPattern{r.Pattern}, doc), apply: r.apply})
+	}
+	return resolved
+}
+
+// applyRules returns opts as overridden by the rules selecting path
+func applyRules(path spec.NormalizedPath, opts Options) Options {
+	for _, r := range opts.rules {
+		if r.paths.contains(path) {
+			r.apply(&opts)
+		}
+	}
+	return opts
+}
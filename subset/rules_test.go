@@ -0,0 +1,205 @@
+package subset
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	rules, err := ParseRules(map[string]string{
+		"$.price":  "epsilon:0.01",
+		"$.id":     "regex",
+		"$.name":   "ignore-case",
+		"$.meta":   "type",
+		"$.amount": "exact",
+		"code":     "ignore-case",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subset := map[string]interface{}{
+		"price":  9.99,
+		"id":     "re:/^user-[0-9]+$/",
+		"name":   "Alice",
+		"meta":   map[string]interface{}{"requestId": "abc", "count": float64(1)},
+		"amount": float64(10),
+		"items":  []interface{}{map[string]interface{}{"code": "X1"}},
+	}
+	superset := map[string]interface{}{
+		"price":  9.991,
+		"id":     "user-42",
+		"name":   "alice",
+		"meta":   map[string]interface{}{"requestId": "xyz", "count": float64(7)},
+		"amount": 10.05,
+		"items":  []interface{}{map[string]interface{}{"code": "x1"}},
+	}
+
+	ok, diffs := CheckSubsetWithOptions(subset, superset, Options{Epsilon: 0.1, Rules: rules})
+	if ok {
+		t.Fatal("the exact rule should override the global epsilon")
+	}
+	if len(diffs) != 1 || diffs[0].Path.String() != "$['amount']" {
+		t.Fatalf("diffs = %+v, want only $['amount']", diffs)
+	}
+
+	superset["amount"] = float64(10)
+	if ok, diffs := CheckSubsetWithOptions(subset, superset, Options{Rules: rules}); !ok {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+
+	// Without rules every overridden field fails.
+	if _, diffs := CheckSubset(subset, superset); len(diffs) != 6 {
+		t.Errorf("got %d diffs without rules, want 6: %+v", len(diffs), diffs)
+	}
+}
+
+func TestRulesApplyToArrayElements(t *testing.T) {
+	rules, err := ParseRules(map[string]string{"$.tags[*]": "ignore-case"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subset := map[string]interface{}{"tags": []interface{}{"Admin"}}
+	superset := map[string]interface{}{"tags": []interface{}{"dev", "admin"}}
+
+	if ok, diffs := CheckSubsetWithOptions(subset, superset, Options{Rules: rules}); !ok {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+}
+
+func TestExactRuleOverrides(t *testing.T) {
+	rules, err := ParseRules(map[string]string{"$.exact": "exact"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		subset   interface{}
+		superset interface{}
+		opts     Options
+	}{
+		{"normalize numbers", float32(0.1), json.Number("0.1"), Options{NormalizeNumbers: true}},
+		{"type widening", "a", []interface{}{"a"}, Options{AllowTypeWidening: true}},
+		{"contains matcher", "contains:err", "an error", Options{EnableMatchers: true}},
+		{"glob matcher", "glob:*.example.com", "api.example.com", Options{EnableMatchers: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subset := map[string]interface{}{"loose": tt.subset, "exact": tt.subset}
+			superset := map[string]interface{}{"loose": tt.superset, "exact": tt.superset}
+			tt.opts.Rules = rules
+			_, diffs := CheckSubsetWithOptions(subset, superset, tt.opts)
+			if len(diffs) != 1 || diffs[0].Path.String() != "$['exact']" {
+				t.Errorf("diffs = %+v, want only $['exact']", diffs)
+			}
+		})
+	}
+}
+
+func TestRulesArrayOrder(t *testing.T) {
+	rules, err := ParseRules(map[string]string{
+		"$.tags":  "set",
+		"$.steps": "ordered",
+		"$.votes": "multiset",
+		"$.users": "ordered",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	subset := map[string]interface{}{
+		"tags":  []interface{}{"b", "a"},
+		"steps": []interface{}{"build", "test"},
+		"votes": []interface{}{"yes", "yes"},
+		"users": []interface{}{map[string]interface{}{"id": "1"}},
+	}
+	superset := map[string]interface{}{
+		"tags":  []interface{}{"a", "b"},
+		"steps": []interface{}{"build", "test", "deploy"},
+		"votes": []interface{}{"yes", "no", "yes"},
+		"users": []interface{}{map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "2"}},
+	}
+
+	tests := []struct {
+		name      string
+		opts      Options
+		mutate    func(superset map[string]interface{})
+		wantPaths []string
+	}{
+		{name: "mixed modes match", opts: Options{Rules: rules}},
+		{name: "set rule overrides global ordered", opts: Options{ArrayOrder: ArrayOrdered, Rules: rules}},
+		{
+			name:      "ordered array out of order",
+			opts:      Options{Rules: rules},
+			mutate:    func(s map[string]interface{}) { s["steps"] = []interface{}{"test", "build"} },
+			wantPaths: []string{"$['steps'][0]", "$['steps'][1]"},
+		},
+		{
+			name:      "multiset counts duplicates",
+			opts:      Options{Rules: rules},
+			mutate:    func(s map[string]interface{}) { s["votes"] = []interface{}{"yes", "no"} },
+			wantPaths: []string{"$['votes'][1]"},
+		},
+		{
+			name: "order rule overrides the array key",
+			opts: Options{ArrayKey: "id", Rules: rules},
+			mutate: func(s map[string]interface{}) {
+				s["users"] = []interface{}{map[string]interface{}{"id": "2"}, map[string]interface{}{"id": "1"}}
+			},
+			wantPaths: []string{"$['users'][0]['id']"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sup := make(map[string]interface{}, len(superset))
+			for k, v := range superset {
+				sup[k] = v
+			}
+			if tt.mutate != nil {
+				tt.mutate(sup)
+			}
+			_, diffs := CheckSubsetWithOptions(subset, sup, tt.opts)
+			var paths []string
+			for _, d := range diffs {
+				paths = append(paths, d.Path.String())
+			}
+			if strings.Join(paths, " ") != strings.Join(tt.wantPaths, " ") {
+				t.Errorf("diff paths = %v, want %v", paths, tt.wantPaths)
+			}
+		})
+	}
+
+	// Without rules, the global mode applies everywhere.
+	if ok, _ := CheckSubsetWithOptions(subset, superset, Options{ArrayOrder: ArrayOrdered}); ok {
+		t.Error("tags in a different order should fail in ordered mode")
+	}
+}
+
+func TestParseRuleErrors(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		directive string
+		wantErr   string
+	}{
+		{"$.a", "fuzzy", "unknown directive"},
+		{"$.a", "epsilon", "unknown directive"},
+		{"$.a", "epsilon:abc", "invalid epsilon"},
+		{"$.a", "epsilon:-1", "invalid epsilon"},
+		{"$.a", "exact:1", "unknown directive"},
+		{"$.a", "ordered:1", "unknown directive"},
+		{"$[", "exact", ""},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseRule(tt.pattern, tt.directive)
+		if err == nil {
+			t.Errorf("ParseRule(%q, %q) succeeded, want error", tt.pattern, tt.directive)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("ParseRule(%q, %q) error = %v, want %q", tt.pattern, tt.directive, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package subset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// CheckSchema validates doc against a JSON Schema, reporting violations as
+// diffs with paths into doc. Only the type, required and enum keywords are
+// checked; properties and items are followed to reach nested values, and
+// every other keyword is ignored. The schema keyword that failed is the
+// diff's SupersetValue, e.g. {"type": "integer"}. It returns an error if
+// the schema is malformed.
+func CheckSchema(doc, schema interface{}) (bool, []Diff, error) {
+	diffs, err := checkSchemaAt(doc, schema, spec.NormalizedPath{})
+	if err != nil {
+		return false, nil, err
+	}
+	return len(diffs) == 0, diffs, nil
+}
+
+func checkSchemaAt(value, schema interface{}, path spec.NormalizedPath) ([]Diff, error) {
+	keywords, ok := schema.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("schema for %s is not an object", path)
+	}
+
+	if t, ok := keywords["type"]; ok {
+		types, err := schemaTypes(t)
+		if err != nil {
+			return nil, fmt.Errorf("schema for %s: %w", path, err)
+		}
+		if !hasSchemaType(value, types) {
+			// Nothing below a value of the wrong type can be checked.
+			return []Diff{{
+				Path:          copyPath(path),
+				Type:          DiffTypeMismatch,
+				SubsetValue:   value,
+				SupersetValue: map[string]interface{}{"type": t},
+				Message:       fmt.Sprintf("want %s, got %s", strings.Join(types, " or "), jsonType(value)),
+			}}, nil
+		}
+	}
+
+	var diffs []Diff
+	if e, ok := keywords["enum"]; ok {
+		values, ok := e.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("schema for %s: enum is not an array", path)
+		}
+		if !containsValue(values, value) {
+			diffs = append(diffs, Diff{
+				Path:          copyPath(path),
+				Type:          DiffValueMismatch,
+				SubsetValue:   value,
+				SupersetValue: map[string]interface{}{"enum": e},
+				Message:       fmt.Sprintf("not one of the %d enum values", len(values)),
+			})
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		required, err := schemaStrings(keywords, "required")
+		if err != nil {
+			return nil, fmt.Errorf("schema for %s: %w", path, err)
+		}
+		for _, key := range required {
+			if _, ok := v[key]; !ok {
+				diffs = append(diffs, Diff{
+					Path:    append(copyPath(path), spec.Name(key)),
+					Type:    DiffMissingKey,
+					Message: "required by the schema",
+				})
+			}
+		}
+
+		properties, ok := keywords["properties"].(map[string]interface{})
+		if !ok && keywords["properties"] != nil {
+			return nil, fmt.Errorf("schema for %s: properties is not an object", path)
+		}
+		keys := make([]string, 0, len(properties))
+		for key := range properties {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			child, ok := v[key]
+			if !ok {
+				continue
+			}
+			childDiffs, err := checkSchemaAt(child, properties[key], append(copyPath(path), spec.Name(key)))
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, childDiffs...)
+		}
+
+	case []interface{}:
+		items, ok := keywords["items"]
+		if !ok {
+			break
+		}
+		for i, elem := range v {
+			elemDiffs, err := checkSchemaAt(elem, items, append(copyPath(path), spec.Index(i)))
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, elemDiffs...)
+		}
+	}
+	return diffs, nil
+}
+
+// schemaTypes returns the type names of a type keyword, which is a name or
+// an array of names
+func schemaTypes(t interface{}) ([]string, error) {
+	var names []interface{}
+	switch v := t.(type) {
+	case string:
+		names = []interface{}{v}
+	case []interface{}:
+		names = v
+	default:
+		return nil, fmt.Errorf("type is not a string or an array")
+	}
+
+	types := make([]string, 0, len(names))
+	for _, n := range names {
+		name, ok := n.(string)
+		if !ok || !(jsonTypes[name] || name == "integer") {
+			return nil, fmt.Errorf("unknown type %v (want null, boolean, string, number, integer, object or array)", n)
+		}
+		types = append(types, name)
+	}
+	return types, nil
+}
+
+// hasSchemaType reports whether value has one of the types. An integer is
+// a number without a fractional part, like 1 or 1.0.
+func hasSchemaType(value interface{}, types []string) bool {
+	got := jsonType(value)
+	for _, t := range types {
+		if t == got {
+			return true
+		}
+		if t == "integer" && got == "number" {
+			if r, ok := decimalNumber(value); ok && r.IsInt() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// schemaStrings returns a keyword holding an array of strings, or nil if
+// it is absent
+func schemaStrings(keywords map[string]interface{}, name string) ([]string, error) {
+	raw, ok := keywords[name]
+	if !ok {
+		return nil, nil
+	}
+	values, ok := raw.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s is not an array", name)
+	}
+	strs := make([]string, len(values))
+	for i, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s[%d] is not a string", name, i)
+		}
+		strs[i] = s
+	}
+	return strs, nil
+}
+
+// containsValue reports whether values holds a value equal to v. Values
+// are equal when each is a subset of the other with ordered arrays of the
+// same length, so numbers compare by value and object key order is moot.
+func containsValue(values []interface{}, v interface{}) bool {
+	opts := Options{ArrayOrder: ArrayOrdered, ArrayExactLength: true}
+	for _, candidate := range values {
+		if ok, _ := CheckSubsetWithOptions(candidate, v, opts); !ok {
+			continue
+		}
+		if ok, _ := CheckSubsetWithOptions(v, candidate, opts); ok {
+			return true
+		}
+	}
+	return false
+}
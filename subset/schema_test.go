@@ -0,0 +1,118 @@
+package subset
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheckSchema(t *testing.T) {
+	const schema = `{
+		"type": "object",
+		"required": ["id", "name"],
+		"properties": {
+			"id": {"type": "integer"},
+			"name": {"type": "string"},
+			"role": {"enum": ["admin", "user"]},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"score": {"type": ["number", "null"]},
+			"address": {"type": "object", "required": ["city"]}
+		}
+	}`
+	tests := []struct {
+		name  string
+		doc   string
+		want  []string
+		types []DiffType
+	}{
+		{
+			name: "valid",
+			doc:  `{"id": 1.0, "name": "alice", "role": "admin", "tags": ["a"], "score": null, "address": {"city": "Oslo"}, "extra": true}`,
+		},
+		{
+			name:  "type violations",
+			doc:   `{"id": 1.5, "name": 7, "tags": ["a", 2], "score": "high"}`,
+			want:  []string{"$['id']", "$['name']", "$['score']", "$['tags'][1]"},
+			types: []DiffType{DiffTypeMismatch, DiffTypeMismatch, DiffTypeMismatch, DiffTypeMismatch},
+		},
+		{
+			name:  "required violations",
+			doc:   `{"address": {}}`,
+			want:  []string{"$['id']", "$['name']", "$['address']['city']"},
+			types: []DiffType{DiffMissingKey, DiffMissingKey, DiffMissingKey},
+		},
+		{
+			name:  "enum violation",
+			doc:   `{"id": 1, "name": "a", "role": "root"}`,
+			want:  []string{"$['role']"},
+			types: []DiffType{DiffValueMismatch},
+		},
+		{
+			name:  "wrong root type",
+			doc:   `[{"id": 1}]`,
+			want:  []string{"$"},
+			types: []DiffType{DiffTypeMismatch},
+		},
+	}
+
+	var s interface{}
+	if err := json.Unmarshal([]byte(schema), &s); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc interface{}
+			dec := json.NewDecoder(strings.NewReader(tt.doc))
+			dec.UseNumber()
+			if err := dec.Decode(&doc); err != nil {
+				t.Fatal(err)
+			}
+
+			ok, diffs, err := CheckSchema(doc, s)
+			if err != nil {
+				t.Fatalf("CheckSchema() error = %v", err)
+			}
+			if ok != (len(tt.want) == 0) {
+				t.Errorf("CheckSchema() = %v, diffs: %+v", ok, diffs)
+			}
+			var paths []string
+			var types []DiffType
+			for _, d := range diffs {
+				paths = append(paths, d.Path.String())
+				types = append(types, d.Type)
+			}
+			if !reflect.DeepEqual(paths, tt.want) || !reflect.DeepEqual(types, tt.types) {
+				t.Errorf("diffs = %v %v, want %v %v", paths, types, tt.want, tt.types)
+			}
+		})
+	}
+
+	_, diffs, _ := CheckSchema(map[string]interface{}{"id": "x", "name": "a"}, s)
+	if len(diffs) != 1 || diffs[0].Message != "want integer, got string" {
+		t.Errorf("diffs = %+v, want a message naming both types", diffs)
+	}
+	if want := map[string]interface{}{"type": "integer"}; !reflect.DeepEqual(diffs[0].SupersetValue, want) {
+		t.Errorf("SupersetValue = %v, want %v", diffs[0].SupersetValue, want)
+	}
+}
+
+func TestCheckSchemaErrors(t *testing.T) {
+	for _, schema := range []string{
+		`[]`,
+		`{"type": "float"}`,
+		`{"type": 1}`,
+		`{"enum": "a"}`,
+		`{"required": "id"}`,
+		`{"properties": []}`,
+		`{"properties": {"a": true}}`,
+	} {
+		var s interface{}
+		if err := json.Unmarshal([]byte(schema), &s); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := CheckSchema(map[string]interface{}{"a": 1}, s); err == nil {
+			t.Errorf("CheckSchema() with schema %s succeeded, want error", schema)
+		}
+	}
+}
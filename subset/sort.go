@@ -0,0 +1,63 @@
+package subset
+
+import (
+	"sort"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// SortArrays returns a copy of doc in which every array of primitives is
+// sorted by the canonical JSON encoding of its elements, so that arrays
+// differing only in order line up index by index under ArrayOrdered.
+// Arrays holding objects or arrays, or numbers too large to encode
+// canonically, keep their order; their paths are returned. doc itself is
+// not modified.
+func SortArrays(doc interface{}) (interface{}, []spec.NormalizedPath) {
+	var unsorted []spec.NormalizedPath
+	sorted := sortArrays(doc, spec.NormalizedPath{}, &unsorted)
+	return sorted, unsorted
+}
+
+func sortArrays(v interface{}, path spec.NormalizedPath, unsorted *[]spec.NormalizedPath) interface{} {
+	switch c := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(c))
+		for key, elem := range c {
+			result[key] = sortArrays(elem, append(copyPath(path), spec.Name(key)), unsorted)
+		}
+		return result
+
+	case []interface{}:
+		result := make([]interface{}, len(c))
+		keys := make([]string, len(c))
+		sortable := true
+		for i, elem := range c {
+			result[i] = sortArrays(elem, append(copyPath(path), spec.Index(i)), unsorted)
+			if key, ok := primitiveKey(elem); ok {
+				keys[i] = key
+			} else {
+				sortable = false
+			}
+		}
+		if !sortable {
+			*unsorted = append(*unsorted, copyPath(path))
+			return result
+		}
+		sort.Stable(byKey{result, keys})
+		return result
+	}
+	return v
+}
+
+// byKey sorts values by their precomputed keys
+type byKey struct {
+	values []interface{}
+	keys   []string
+}
+
+func (b byKey) Len() int           { return len(b.values) }
+func (b byKey) Less(i, j int) bool { return b.keys[i] < b.keys[j] }
+func (b byKey) Swap(i, j int) {
+	b.values[i], b.values[j] = b.values[j], b.values[i]
+	b.keys[i], b.keys[j] = b.keys[j], b.keys[i]
+}
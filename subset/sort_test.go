@@ -0,0 +1,58 @@
+package subset
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSortArrays(t *testing.T) {
+	doc := map[string]interface{}{
+		"nums":   []interface{}{float64(3), float64(1), float64(2)},
+		"mixed":  []interface{}{"b", float64(1), nil, true, "a"},
+		"big":    []interface{}{json.Number("12345678901234567891"), json.Number("1")},
+		"nested": []interface{}{[]interface{}{"z", "y"}, map[string]interface{}{"tags": []interface{}{"q", "p"}}},
+	}
+	original := map[string]interface{}{
+		"nums":   []interface{}{float64(3), float64(1), float64(2)},
+		"mixed":  []interface{}{"b", float64(1), nil, true, "a"},
+		"big":    []interface{}{json.Number("12345678901234567891"), json.Number("1")},
+		"nested": []interface{}{[]interface{}{"z", "y"}, map[string]interface{}{"tags": []interface{}{"q", "p"}}},
+	}
+
+	got, unsorted := SortArrays(doc)
+	want := map[string]interface{}{
+		"nums":   []interface{}{float64(1), float64(2), float64(3)},
+		"mixed":  []interface{}{"a", "b", float64(1), nil, true},
+		"big":    []interface{}{json.Number("12345678901234567891"), json.Number("1")},
+		"nested": []interface{}{[]interface{}{"y", "z"}, map[string]interface{}{"tags": []interface{}{"p", "q"}}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SortArrays() = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(doc, original) {
+		t.Errorf("SortArrays() modified its argument: %v", doc)
+	}
+
+	var paths []string
+	for _, p := range unsorted {
+		paths = append(paths, p.String())
+	}
+	wantPaths := map[string]bool{"$['big']": true, "$['nested']": true}
+	if len(paths) != len(wantPaths) || !wantPaths[paths[0]] || !wantPaths[paths[1]] {
+		t.Errorf("unsorted = %v, want %v", paths, wantPaths)
+	}
+}
+
+func TestSortArraysOrdered(t *testing.T) {
+	subset, _ := SortArrays([]interface{}{float64(3), float64(1), float64(2)})
+	superset, _ := SortArrays([]interface{}{float64(1), float64(2), float64(3)})
+	opts := Options{ArrayOrder: ArrayOrdered}
+
+	if ok, diffs := CheckSubsetWithOptions(subset, superset, opts); !ok {
+		t.Errorf("sorted arrays should match in ordered mode: %+v", diffs)
+	}
+	if ok, _ := CheckSubsetWithOptions([]interface{}{float64(3), float64(1), float64(2)}, []interface{}{float64(1), float64(2), float64(3)}, opts); ok {
+		t.Error("unsorted arrays should not match in ordered mode")
+	}
+}
@@ -0,0 +1,19 @@
+package subset
+
+import "fmt"
+
+// Stats counts the work done by a comparison. Pass a non-nil *Stats in
+// Options to collect it; a nil Stats costs nothing.
+type Stats struct {
+	// ObjectKeys is the number of subset object keys visited
+	ObjectKeys int
+	// ArrayElements is the number of subset array elements visited
+	ArrayElements int
+	// Primitives is the number of leaf comparisons performed
+	Primitives int
+}
+
+// String returns a line like "3 object keys, 2 array elements, 4 primitive comparisons"
+func (s *Stats) String() string {
+	return fmt.Sprintf("%d object keys, %d array elements, %d primitive comparisons", s.ObjectKeys, s.ArrayElements, s.Primitives)
+}
@@ -0,0 +1,44 @@
+package subset
+
+import "testing"
+
+func TestStats(t *testing.T) {
+	subset := map[string]interface{}{
+		"name": "alice",
+		"tags": []interface{}{"b", "a"},
+		"user": map[string]interface{}{"id": float64(1)},
+	}
+	superset := map[string]interface{}{
+		"name": "alice",
+		"tags": []interface{}{"a", "b"},
+		"user": map[string]interface{}{"id": float64(1), "age": float64(30)},
+	}
+
+	var stats Stats
+	if ok, diffs := CheckSubsetWithOptions(subset, superset, Options{Stats: &stats}); !ok {
+		t.Fatalf("unexpected diffs: %+v", diffs)
+	}
+	// Arrays of primitives are looked up by hash, one comparison per element.
+	want := Stats{ObjectKeys: 4, ArrayElements: 2, Primitives: 4}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+	if got := stats.String(); got != "4 object keys, 2 array elements, 4 primitive comparisons" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestStatsCountsEveryAttempt(t *testing.T) {
+	subset := []interface{}{map[string]interface{}{"id": float64(2)}}
+	superset := []interface{}{
+		map[string]interface{}{"id": float64(1)},
+		map[string]interface{}{"id": float64(2)},
+	}
+
+	var stats Stats
+	CheckSubsetWithOptions(subset, superset, Options{Stats: &stats})
+	want := Stats{ObjectKeys: 2, ArrayElements: 1, Primitives: 2}
+	if stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
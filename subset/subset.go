@@ -0,0 +1,943 @@
+// Package subset checks whether one decoded JSON value is contained in another.
+package subset
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// ArrayOrder represents how arrays are compared
+type ArrayOrder int
+
+const (
+	// ArraySet ignores element order
+	ArraySet ArrayOrder = iota
+	// ArrayOrdered compares elements at the same index
+	ArrayOrdered
+	// ArrayMultiset ignores element order but counts duplicates
+	ArrayMultiset
+)
+
+// Options controls how values are compared
+type Options struct {
+	ArrayOrder ArrayOrder
+	// Epsilon is the maximum absolute difference for two numbers to be equal
+	Epsilon float64
+	// NormalizeNumbers compares numbers by decimal value, taking floats at
+	// their shortest decimal spelling, so no float rounding can make two
+	// numbers equal. An Epsilon takes precedence.
+	NormalizeNumbers bool
+	// IgnoreCase compares string values case-insensitively
+	IgnoreCase bool
+	// TrimStrings ignores leading and trailing whitespace in string values
+	TrimStrings bool
+	// EmptyEqualsNull lets the empty string and null match each other,
+	// in either direction
+	EmptyEqualsNull bool
+	// CoerceBool lets the strings "true" and "false" equal the booleans
+	CoerceBool bool
+	// AllowTypeWidening lets a scalar subset value match a superset array
+	// holding exactly one element equal to it, so 1 matches [1]
+	AllowTypeWidening bool
+	// StrictTypes only lets primitives of the same type and value match,
+	// and numbers only with the same spelling, so json.Number("1.0")
+	// differs from json.Number("1") and from float64(1), while
+	// json.Number("1") equals float64(1) as decoded from YAML or TOML. It
+	// overrides Epsilon, NormalizeNumbers, EmptyEqualsNull and CoerceBool,
+	// including when set by rules.
+	StrictTypes bool
+	// IgnoreKeyCase matches object keys case-insensitively
+	IgnoreKeyCase bool
+	// IgnoreKeyWhitespace matches object keys ignoring leading and trailing
+	// whitespace, so " name" finds "name"
+	IgnoreKeyWhitespace bool
+	// EnableRegex treats subset strings of the form "re:/pattern/" as regular expressions
+	EnableRegex bool
+	// EnableMatchers treats subset strings with a matcher prefix such as
+	// "contains:", "glob:" or "$type:" as checks on the superset value instead of literals
+	EnableMatchers bool
+	// Ignore lists subset locations that are skipped during comparison
+	Ignore []PathPattern
+	// Only, if set, restricts the comparison to the subset locations these
+	// patterns select, and everything inside them; the rest is skipped
+	// like Ignore
+	Only []PathPattern
+	// ParseEmbedded lists subset locations holding JSON documents as
+	// strings, which are decoded on both sides and compared structurally
+	ParseEmbedded []PathPattern
+	// TimeFields lists subset locations holding RFC 3339 times, which are
+	// compared by instant instead of as strings
+	TimeFields []PathPattern
+	// KeyMap looks up renamed keys in the superset under a different name
+	KeyMap []KeyMapping
+	// LimitDepth stops the comparison below MaxDepth. Object keys at
+	// MaxDepth+1 must still be present, but their values are not compared,
+	// so a MaxDepth of 0 only checks the presence of the top-level keys.
+	LimitDepth bool
+	MaxDepth   int
+	// FirstKeys, if positive, only compares the first FirstKeys keys of
+	// each subset object in sorted order, which helps narrow down which
+	// part of a large subset fails
+	FirstKeys int
+	// ArrayKey, if set, pairs object elements of arrays by the value of
+	// this key instead of by whole-element equality, then compares each
+	// pair. It overrides ArrayOrder for subset elements that have the key.
+	ArrayKey string
+	// ArrayAsObject lets an array match an object whose keys are all array
+	// indexes, pairing element i with key "i", in either direction
+	ArrayAsObject bool
+	// ArrayExactLength additionally requires arrays to have the same length
+	ArrayExactLength bool
+	// IgnoreValues only requires primitives to have the same JSON type
+	IgnoreValues bool
+	// ShowExtra reports superset keys missing from the subset as DiffExtraKey
+	// diffs. They are informational and do not make the check fail.
+	ShowExtra bool
+	// EnableWildcard treats a subset key "*" as matching any superset key
+	// whose value contains the associated value
+	EnableWildcard bool
+	// RequiredKeys lists keys that every object in the superset must have,
+	// whether or not the subset mentions them
+	RequiredKeys []string
+	// Stats, if set, is incremented as values are compared. Set mode arrays
+	// may compare an element several times, and each attempt is counted.
+	Stats *Stats
+	// Progress, if set, is called with the number of values compared so
+	// far, counted like Stats, at most once per ProgressInterval while the
+	// comparison runs. Calls never overlap, even with Parallel.
+	Progress func(values int)
+	// ProgressInterval throttles Progress; 0 means DefaultProgressInterval
+	ProgressInterval time.Duration
+	// NullMeansOptional lets a null subset value also match a missing key
+	NullMeansOptional bool
+	// IgnoreNullValues skips subset keys whose value is null, so null
+	// works as a "don't care" placeholder: neither the key's presence nor
+	// its value is checked
+	IgnoreNullValues bool
+	// Intersection only compares keys present on both sides; subset keys
+	// missing from the superset are skipped instead of reported
+	Intersection bool
+	// Parallel compares the branches of a top-level object in up to this
+	// many goroutines. Values below 2, or FailFast, compare sequentially.
+	// The result is the same either way.
+	Parallel int
+	// FailFast stops at the first difference that fails the check, so at
+	// most one such diff is returned
+	FailFast bool
+	// Rules override the options above for parts of the subset
+	Rules []Rule
+
+	ignored    *pathSet
+	embedded   *pathSet
+	timeFields *pathSet
+	progress   *progressTracker
+	stream     *diffStream
+	visiting   *visitFrame
+	rules      []resolvedRule
+	keyMap     []resolvedMapping
+	matches    *matchRecorder
+	// trial marks a comparison whose diffs are thrown away, such as the
+	// search for a matching array element, so no closest match is looked for
+	trial bool
+}
+
+// stop reports whether a FailFast comparison is over
+func (opts Options) stop(isSubset bool) bool {
+	return (opts.FailFast && !isSubset) || opts.stream.done()
+}
+
+// ParseArrayOrder converts a name such as "set" into an ArrayOrder
+func ParseArrayOrder(s string) (ArrayOrder, error) {
+	switch s {
+	case "set":
+		return ArraySet, nil
+	case "ordered":
+		return ArrayOrdered, nil
+	case "multiset":
+		return ArrayMultiset, nil
+	default:
+		return ArraySet, fmt.Errorf("invalid array order %q (want set, ordered or multiset)", s)
+	}
+}
+
+// CheckSubset checks if subset is a subset of superset using the default options.
+func CheckSubset(subset, superset interface{}) (bool, []Diff) {
+	return CheckSubsetWithOptions(subset, superset, Options{})
+}
+
+// CheckSubsetWithOptions checks if subset is a subset of superset.
+// Values built by hand may contain themselves; such a cycle is reported
+// as a diff instead of being followed forever.
+func CheckSubsetWithOptions(subset, superset interface{}, opts Options) (bool, []Diff) {
+	opts = prepareOptions(subset, opts)
+	isSubset, diffs := checkSubsetPath(subset, superset, spec.NormalizedPath{}, opts)
+	if len(opts.RequiredKeys) > 0 && !opts.stop(isSubset) {
+		required := requiredKeyDiffs(superset, spec.NormalizedPath{}, requiredOptions(superset, opts))
+		if len(required) > 0 {
+			if opts.FailFast {
+				required = required[:1]
+			}
+			isSubset = false
+			diffs = append(diffs, required...)
+		}
+	}
+	return isSubset, diffs
+}
+
+// prepareOptions resolves the patterns in opts against the subset
+func prepareOptions(subset interface{}, opts Options) Options {
+	opts.ignored = newPathSet(opts.Ignore, subset)
+	if len(opts.Only) > 0 {
+		opts.ignored = ignoreUnselected(opts.ignored, newPathSet(opts.Only, subset), subset)
+	}
+	opts.embedded = newPathSet(opts.ParseEmbedded, subset)
+	opts.timeFields = newPathSet(opts.TimeFields, subset)
+	opts.rules = resolveRules(opts.Rules, subset)
+	opts.keyMap = resolveKeyMap(opts.KeyMap, subset)
+	opts.progress = newProgressTracker(opts)
+	return opts
+}
+
+// CheckSubsetExplain is like CheckSubsetWithOptions but also returns the
+// paths of the subset leaves that matched, for use with FormatOptions.Matched.
+func CheckSubsetExplain(subset, superset interface{}, opts Options) (bool, []Diff, []spec.NormalizedPath) {
+	opts.matches = &matchRecorder{}
+	ok, diffs := CheckSubsetWithOptions(subset, superset, opts)
+	return ok, diffs, opts.matches.paths
+}
+
+// matchRecorder collects the paths of matched leaves. A nil recorder
+// records nothing, so the normal check pays no cost.
+type matchRecorder struct {
+	paths []spec.NormalizedPath
+}
+
+func (r *matchRecorder) add(path spec.NormalizedPath) {
+	if r != nil {
+		r.paths = append(r.paths, copyPath(path))
+	}
+}
+
+// tryMatch runs a comparison whose leaf matches are kept only if the whole
+// value matches, as when searching a superset array for an element.
+func tryMatch(subset, superset interface{}, path spec.NormalizedPath, opts Options) bool {
+	parent := opts.matches
+	if parent != nil {
+		opts.matches = &matchRecorder{}
+	}
+	opts.stream = nil
+	opts.trial = true
+	ok, _ := checkSubsetPath(subset, superset, path, opts)
+	if ok && parent != nil {
+		parent.paths = append(parent.paths, opts.matches.paths...)
+	}
+	return ok
+}
+
+func checkSubsetPath(subset, superset interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	ok, diffs := compareAt(subset, superset, path, opts)
+	opts.stream.emit(diffs)
+	return ok, diffs
+}
+
+// compareAt does the work of checkSubsetPath
+func compareAt(subset, superset interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	if opts.LimitDepth && len(path) > opts.MaxDepth {
+		return true, nil
+	}
+	if len(opts.rules) > 0 {
+		opts = applyRules(path, opts)
+	}
+	if first, ok := enterContainer(subset, path, &opts); !ok {
+		return false, []Diff{{
+			Path:    copyPath(path),
+			Type:    DiffValueMismatch,
+			Message: fmt.Sprintf("cyclic structure: the subset value contains itself, first compared at %s", first),
+		}}
+	}
+	if ok, diffs, handled := checkEmbedded(subset, superset, path, opts); handled {
+		return ok, diffs
+	}
+	if opts.ArrayAsObject {
+		if ok, diffs, handled := checkArrayAsObject(subset, superset, path, opts); handled {
+			return ok, diffs
+		}
+	}
+
+	subsetMap, subsetIsMap := toObject(subset)
+	supersetMap, supersetIsMap := toObject(superset)
+
+	subsetArr, subsetIsArr := subset.([]interface{})
+	supersetArr, supersetIsArr := superset.([]interface{})
+
+	if subsetIsMap && !supersetIsMap {
+		return false, []Diff{{Path: copyPath(path), Type: DiffTypeMismatch, SubsetValue: subset, SupersetValue: superset}}
+	}
+	if subsetIsArr && !supersetIsArr {
+		return false, []Diff{{Path: copyPath(path), Type: DiffTypeMismatch, SubsetValue: subset, SupersetValue: superset}}
+	}
+
+	if subsetIsMap {
+		n := len(subsetMap)
+		if opts.FirstKeys > 0 {
+			n = min(n, opts.FirstKeys)
+		}
+		if opts.Stats != nil {
+			opts.Stats.ObjectKeys += n
+		}
+		opts.progress.add(n)
+		return checkObjectSubset(subsetMap, supersetMap, path, opts)
+	}
+	if subsetIsArr {
+		if opts.Stats != nil {
+			opts.Stats.ArrayElements += len(subsetArr)
+		}
+		opts.progress.add(len(subsetArr))
+		return checkArraySubset(subsetArr, supersetArr, path, opts)
+	}
+
+	if opts.AllowTypeWidening && supersetIsArr && len(supersetArr) == 1 {
+		superset = supersetArr[0]
+	}
+	if opts.Stats != nil {
+		opts.Stats.Primitives++
+	}
+	opts.progress.add(1)
+	ok, diffs := checkPrimitive(subset, superset, path, opts)
+	if ok {
+		opts.matches.add(path)
+	}
+	return ok, diffs
+}
+
+// checkPrimitive compares a subset leaf (string, number, bool or null)
+func checkPrimitive(subset, superset interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	if opts.StrictTypes {
+		opts.Epsilon, opts.NormalizeNumbers = 0, false
+		opts.EmptyEqualsNull, opts.CoerceBool = false, false
+	}
+	if opts.EmptyEqualsNull && emptyOrNull(subset) && emptyOrNull(superset) {
+		return true, nil
+	}
+	if subset == nil {
+		if superset == nil {
+			return true, nil
+		}
+		return false, []Diff{{Path: copyPath(path), Type: DiffValueMismatch, SubsetValue: subset, SupersetValue: superset}}
+	}
+
+	if opts.IgnoreValues {
+		if jsonType(subset) == jsonType(superset) {
+			return true, nil
+		}
+		return false, []Diff{{Path: copyPath(path), Type: DiffTypeMismatch, SubsetValue: subset, SupersetValue: superset}}
+	}
+
+	if opts.EnableRegex {
+		if pattern, ok := regexPattern(subset); ok {
+			return checkRegex(pattern, superset, path)
+		}
+	}
+	if opts.EnableMatchers {
+		if ok, diffs, handled := checkTypeToken(subset, superset, path); handled {
+			return ok, diffs
+		}
+		if ok, diffs, handled := checkMatcher(subset, superset, path); handled {
+			return ok, diffs
+		}
+	}
+
+	if ok, diffs, handled := checkTime(subset, superset, path, opts); handled {
+		return ok, diffs
+	}
+
+	if primitivesEqual(subset, superset) {
+		return true, nil
+	}
+	if opts.CoerceBool {
+		if subsetBool, ok := coerceBool(subset); ok {
+			if supersetBool, ok := coerceBool(superset); ok && subsetBool == supersetBool {
+				return true, nil
+			}
+		}
+	}
+	if opts.IgnoreCase || opts.TrimStrings {
+		if subsetStr, ok := subset.(string); ok {
+			if supersetStr, ok := superset.(string); ok && stringsEqual(subsetStr, supersetStr, opts) {
+				return true, nil
+			}
+		}
+	}
+	if opts.StrictTypes {
+		diff := Diff{Path: copyPath(path), Type: DiffValueMismatch, SubsetValue: subset, SupersetValue: superset}
+		subsetNum, subsetIsNum := numberSpelling(subset)
+		supersetNum, supersetIsNum := numberSpelling(superset)
+		if subsetIsNum && supersetIsNum {
+			if subsetNum == supersetNum {
+				return true, nil
+			}
+			if equal, _ := numbersEqual(subset, superset, 0); equal {
+				diff.Message = fmt.Sprintf("strict types: subset is spelled %s, superset is spelled %s", subsetNum, supersetNum)
+			}
+		}
+		return false, []Diff{diff}
+	}
+	equal, _ := numbersEqual(subset, superset, opts.Epsilon)
+	if opts.NormalizeNumbers && opts.Epsilon == 0 {
+		if decimalEqual, ok := decimalsEqual(subset, superset); ok {
+			equal = decimalEqual
+		}
+	}
+	if equal {
+		return true, nil
+	}
+	return false, []Diff{{Path: copyPath(path), Type: DiffValueMismatch, SubsetValue: subset, SupersetValue: superset}}
+}
+
+// primitivesEqual reports whether two leaves have the same type and value.
+// Leaves are normally JSON primitives, but values handed in by library
+// callers may be of any type, including slices and maps of other element
+// types that == would panic on.
+func primitivesEqual(a, b interface{}) bool {
+	t := reflect.TypeOf(a)
+	if t != reflect.TypeOf(b) {
+		return false
+	}
+	if t == nil {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Array, reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Pointer, reflect.Slice, reflect.Struct, reflect.UnsafePointer:
+		return reflect.DeepEqual(a, b)
+	}
+	return a == b
+}
+
+// emptyOrNull reports whether v is null or the empty string, which are
+// equal under EmptyEqualsNull
+func emptyOrNull(v interface{}) bool {
+	return v == nil || v == ""
+}
+
+// coerceBool returns the boolean a value stands for under CoerceBool: a
+// bool, or exactly the string "true" or "false"
+func coerceBool(v interface{}) (bool, bool) {
+	switch b := v.(type) {
+	case bool:
+		return b, true
+	case string:
+		switch b {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// stringsEqual compares two strings as IgnoreCase and TrimStrings ask
+func stringsEqual(a, b string, opts Options) bool {
+	if opts.TrimStrings {
+		a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	}
+	if opts.IgnoreCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func checkObjectSubset(subset, superset map[string]interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	var diffs []Diff
+	isSubset := true
+	matchedKeys := make(map[string]bool)
+
+	keys := make([]string, 0, len(subset))
+	for k := range subset {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if opts.FirstKeys > 0 && len(keys) > opts.FirstKeys {
+		keys = keys[:opts.FirstKeys]
+	}
+
+	var results []keyResult
+	if opts.Parallel > 1 && len(path) == 0 && !opts.FailFast {
+		results = checkKeysParallel(keys, subset, superset, path, opts)
+	}
+
+	for i, key := range keys {
+		if opts.stop(isSubset) {
+			return false, diffs
+		}
+		var r keyResult
+		if results != nil {
+			r = results[i]
+		} else {
+			r = checkObjectKey(key, subset, superset, path, opts)
+		}
+
+		if r.supersetKey != "" {
+			matchedKeys[r.supersetKey] = true
+		}
+		if !r.ok {
+			isSubset = false
+		}
+		// Informational diffs such as extra keys are kept even when the values match.
+		opts.stream.emit(r.diffs)
+		diffs = append(diffs, r.diffs...)
+	}
+
+	if opts.ShowExtra && !opts.stop(isSubset) {
+		diffs = append(diffs, extraKeyDiffs(superset, matchedKeys, path)...)
+	}
+
+	return isSubset, diffs
+}
+
+// keyResult is the outcome of comparing one subset key
+type keyResult struct {
+	ok    bool
+	diffs []Diff
+	// supersetKey is the superset key the subset key matched, if any
+	supersetKey string
+}
+
+// checkObjectKey compares the value of one subset key with the superset
+func checkObjectKey(key string, subset, superset map[string]interface{}, path spec.NormalizedPath, opts Options) keyResult {
+	childPath := append(copyPath(path), spec.Name(key))
+	if opts.ignored.contains(childPath) {
+		return keyResult{ok: true}
+	}
+
+	subsetValue := subset[key]
+	if subsetValue == nil && opts.IgnoreNullValues {
+		return keyResult{ok: true}
+	}
+	if opts.EnableWildcard && key == wildcardKey {
+		if !matchAnyValue(subsetValue, superset, childPath, opts) {
+			return keyResult{diffs: []Diff{{Path: childPath, Type: DiffElementNotFound, SubsetValue: subsetValue, Message: "no value in the object matches"}}}
+		}
+		return keyResult{ok: true}
+	}
+
+	wantKey, mapped := mappedKey(childPath, opts)
+	supersetKey, exists := lookupKey(superset, wantKey, opts)
+	if !exists {
+		if opts.Intersection || (subsetValue == nil && opts.NullMeansOptional) {
+			return keyResult{ok: true}
+		}
+		diff := Diff{Path: childPath, Type: DiffMissingKey, SubsetValue: subsetValue}
+		if mapped {
+			diff.Message = fmt.Sprintf("mapped to superset key %q", wantKey)
+		}
+		return keyResult{diffs: []Diff{diff}}
+	}
+
+	ok, diffs := checkSubsetPath(subsetValue, superset[supersetKey], childPath, opts)
+	return keyResult{ok: ok, diffs: diffs, supersetKey: supersetKey}
+}
+
+// checkKeysParallel compares the keys in up to opts.Parallel goroutines.
+// Each key counts into its own Stats and matchRecorder, which are merged
+// in key order, so the result is the same as comparing sequentially.
+func checkKeysParallel(keys []string, subset, superset map[string]interface{}, path spec.NormalizedPath, opts Options) []keyResult {
+	results := make([]keyResult, len(keys))
+	stats := make([]Stats, len(keys))
+	recorders := make([]matchRecorder, len(keys))
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, opts.Parallel)
+	for i, key := range keys {
+		branch := opts
+		if opts.Stats != nil {
+			branch.Stats = &stats[i]
+		}
+		if opts.matches != nil {
+			branch.matches = &recorders[i]
+		}
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = checkObjectKey(key, subset, superset, path, branch)
+		}()
+	}
+	wg.Wait()
+
+	for i := range keys {
+		if opts.Stats != nil {
+			opts.Stats.ObjectKeys += stats[i].ObjectKeys
+			opts.Stats.ArrayElements += stats[i].ArrayElements
+			opts.Stats.Primitives += stats[i].Primitives
+		}
+		if opts.matches != nil {
+			opts.matches.paths = append(opts.matches.paths, recorders[i].paths...)
+		}
+	}
+	return results
+}
+
+// extraKeyDiffs reports superset keys that no subset key asserted
+func extraKeyDiffs(superset map[string]interface{}, matchedKeys map[string]bool, path spec.NormalizedPath) []Diff {
+	keys := make([]string, 0, len(superset))
+	for k := range superset {
+		if !matchedKeys[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	diffs := make([]Diff, 0, len(keys))
+	for _, k := range keys {
+		childPath := append(copyPath(path), spec.Name(k))
+		diffs = append(diffs, Diff{Path: childPath, Type: DiffExtraKey, SupersetValue: superset[k]})
+	}
+	return diffs
+}
+
+// wildcardKey is the subset key matching any superset key
+const wildcardKey = "*"
+
+// matchAnyValue reports whether some value of the superset object contains subsetValue
+func matchAnyValue(subsetValue interface{}, superset map[string]interface{}, path spec.NormalizedPath, opts Options) bool {
+	keys := make([]string, 0, len(superset))
+	for k := range superset {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if tryMatch(subsetValue, superset[k], path, opts) {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupKey finds the superset key matching a subset key. An exact match
+// wins over one found by ignoring case or surrounding whitespace.
+func lookupKey(superset map[string]interface{}, key string, opts Options) (string, bool) {
+	if _, exists := superset[key]; exists {
+		return key, true
+	}
+	if !opts.IgnoreKeyCase && !opts.IgnoreKeyWhitespace {
+		return "", false
+	}
+
+	keys := make([]string, 0, len(superset))
+	for k := range superset {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if keysMatch(k, key, opts) {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// keysMatch compares two object keys under the key options
+func keysMatch(a, b string, opts Options) bool {
+	if opts.IgnoreKeyWhitespace {
+		a, b = strings.TrimSpace(a), strings.TrimSpace(b)
+	}
+	if opts.IgnoreKeyCase {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
+}
+
+func checkArraySubset(subset, superset []interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	var diffs []Diff
+	isSubset := true
+
+	if opts.ArrayExactLength && len(subset) != len(superset) {
+		isSubset = false
+		diffs = append(diffs, Diff{
+			Path:          copyPath(path),
+			Type:          DiffArrayLengthMismatch,
+			SubsetValue:   subset,
+			SupersetValue: superset,
+			Message:       fmt.Sprintf("subset has %d elements, superset has %d", len(subset), len(superset)),
+		})
+		opts.stream.emit(diffs)
+		if opts.FailFast {
+			return false, diffs
+		}
+	}
+
+	var ok bool
+	var elemDiffs []Diff
+	switch {
+	case opts.ArrayKey != "":
+		ok, elemDiffs = checkKeyedArraySubset(subset, superset, path, opts)
+	case opts.ArrayOrder == ArrayOrdered:
+		ok, elemDiffs = checkOrderedArraySubset(subset, superset, path, opts)
+	case opts.ArrayOrder == ArrayMultiset:
+		ok, elemDiffs = checkMultisetArraySubset(subset, superset, path, opts)
+	default:
+		ok, elemDiffs = checkSetArraySubset(subset, superset, path, opts)
+	}
+
+	return isSubset && ok, append(diffs, elemDiffs...)
+}
+
+// checkSetArraySubset ignores order; each subset element must match some superset element.
+func checkSetArraySubset(subset, superset []interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	if ok, diffs, handled := checkHashedArraySubset(subset, superset, path, opts); handled {
+		return ok, diffs
+	}
+	return scanSetArraySubset(subset, superset, path, opts)
+}
+
+// scanSetArraySubset compares every subset element with every superset element
+func scanSetArraySubset(subset, superset []interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	var diffs []Diff
+	isSubset := true
+
+	for i, subsetElem := range subset {
+		if opts.stop(isSubset) {
+			return false, diffs
+		}
+		childPath := append(copyPath(path), spec.Index(i))
+		if opts.ignored.contains(childPath) {
+			continue
+		}
+
+		found := false
+		for _, supersetElem := range superset {
+			if tryMatch(subsetElem, supersetElem, childPath, opts) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			isSubset = false
+			diff := Diff{Path: childPath, Type: DiffElementNotFound, SubsetValue: subsetElem}
+			if opts.trial {
+				diffs = append(diffs, diff)
+				continue
+			}
+			if candidate, count, total := closestElement(subsetElem, superset, childPath, opts); candidate != nil {
+				diff.Message = fmt.Sprintf("closest match is superset index %d (%d of %d keys match)", candidate.Index, count, total)
+				diff.Candidate = candidate
+			}
+			diffs = append(diffs, diff)
+		}
+	}
+
+	return isSubset, diffs
+}
+
+// closestElement returns the superset object that best matches an object
+// subset element, as chosen by closestIndex. The candidate holds the
+// differences from that object. It also returns the number of matching keys
+// and of keys compared. The candidate is nil if the element is not an object
+// or nothing matches anywhere.
+func closestElement(subsetElem interface{}, superset []interface{}, path spec.NormalizedPath, opts Options) (*Candidate, int, int) {
+	j, keys := closestIndex(subsetElem, superset, path, opts)
+	if j < 0 {
+		return nil, 0, 0
+	}
+	opts.FailFast = false
+	opts.stream = nil
+	opts.matches = nil
+	count, diffs := compareElementKeys(keys, subsetElem, superset[j], path, opts)
+	return &Candidate{Index: j, Value: superset[j], Diffs: diffs}, count, len(keys)
+}
+
+// closestIndex returns the index of the superset object that best matches
+// an object subset element, scored by how many subset leaves it matches,
+// then by how many keys, and the subset keys compared; ignored keys, and
+// null placeholders with IgnoreNullValues, are left out. Superset elements
+// of other types, as in mixed arrays, are skipped. Scoring runs as a trial,
+// so no closest matches are searched for inside it. The index is -1 if the
+// element is not an object or nothing matches anywhere.
+func closestIndex(subsetElem interface{}, superset []interface{}, path spec.NormalizedPath, opts Options) (int, []string) {
+	subsetMap, ok := toObject(subsetElem)
+	if !ok {
+		return -1, nil
+	}
+	opts.FailFast = false
+	opts.stream = nil
+	opts.trial = true
+
+	keys := make([]string, 0, len(subsetMap))
+	for key, value := range subsetMap {
+		if value == nil && opts.IgnoreNullValues {
+			continue
+		}
+		if !opts.ignored.contains(append(copyPath(path), spec.Name(key))) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	best := -1
+	bestLeaves, bestCount := 0, 0
+	for j, supersetElem := range superset {
+		if _, ok := toObject(supersetElem); !ok {
+			continue
+		}
+		opts.matches = &matchRecorder{}
+		count, _ := compareElementKeys(keys, subsetElem, supersetElem, path, opts)
+		leaves := len(opts.matches.paths)
+		if leaves > bestLeaves || (leaves == bestLeaves && count > bestCount) {
+			best = j
+			bestLeaves, bestCount = leaves, count
+		}
+	}
+	return best, keys
+}
+
+// compareElementKeys compares the given keys of two objects, returning how
+// many match and the differences
+func compareElementKeys(keys []string, subsetElem, supersetElem interface{}, path spec.NormalizedPath, opts Options) (int, []Diff) {
+	subsetMap, _ := toObject(subsetElem)
+	supersetMap, _ := toObject(supersetElem)
+	count := 0
+	var diffs []Diff
+	for _, key := range keys {
+		keyPath := append(copyPath(path), spec.Name(key))
+		supersetKey, exists := lookupKey(supersetMap, key, opts)
+		if !exists {
+			diffs = append(diffs, Diff{Path: keyPath, Type: DiffMissingKey, SubsetValue: subsetMap[key]})
+			continue
+		}
+		ok, keyDiffs := checkSubsetPath(subsetMap[key], supersetMap[supersetKey], keyPath, opts)
+		if ok {
+			count++
+		}
+		diffs = append(diffs, keyDiffs...)
+	}
+	return count, diffs
+}
+
+// checkOrderedArraySubset compares elements at the same index.
+// A shorter subset is treated as a prefix of the superset.
+func checkOrderedArraySubset(subset, superset []interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	var diffs []Diff
+	isSubset := true
+
+	for i, subsetElem := range subset {
+		if opts.stop(isSubset) {
+			return false, diffs
+		}
+		childPath := append(copyPath(path), spec.Index(i))
+		if opts.ignored.contains(childPath) {
+			continue
+		}
+		if i >= len(superset) {
+			isSubset = false
+			diffs = append(diffs, Diff{Path: childPath, Type: DiffElementNotFound, SubsetValue: subsetElem})
+			continue
+		}
+
+		ok, childDiffs := checkSubsetPath(subsetElem, superset[i], childPath, opts)
+		if !ok {
+			isSubset = false
+		}
+		diffs = append(diffs, childDiffs...)
+	}
+
+	return isSubset, diffs
+}
+
+// checkMultisetArraySubset ignores order but lets each superset element
+// satisfy only one subset element, so duplicates must be matched by duplicates.
+func checkMultisetArraySubset(subset, superset []interface{}, path spec.NormalizedPath, opts Options) (bool, []Diff) {
+	// Matches are recorded once the final assignment is known.
+	probe := opts
+	probe.matches = nil
+	probe.stream = nil
+	probe.trial = true
+
+	candidates := make([][]int, len(subset))
+	for i, subsetElem := range subset {
+		childPath := append(copyPath(path), spec.Index(i))
+		if opts.ignored.contains(childPath) {
+			continue
+		}
+		for j, supersetElem := range superset {
+			if ok, _ := checkSubsetPath(subsetElem, supersetElem, childPath, probe); ok {
+				candidates[i] = append(candidates[i], j)
+			}
+		}
+	}
+
+	// owner[j] is the subset index currently consuming superset[j], or -1
+	owner := make([]int, len(superset))
+	for j := range owner {
+		owner[j] = -1
+	}
+
+	var diffs []Diff
+	isSubset := true
+
+	for i, subsetElem := range subset {
+		if opts.stop(isSubset) {
+			return false, diffs
+		}
+		childPath := append(copyPath(path), spec.Index(i))
+		if opts.ignored.contains(childPath) || assignElement(i, candidates, owner, make([]bool, len(superset))) {
+			continue
+		}
+
+		occurrences := 0
+		for _, other := range subset {
+			if reflect.DeepEqual(subsetElem, other) {
+				occurrences++
+			}
+		}
+
+		isSubset = false
+		diffs = append(diffs, Diff{
+			Path:        childPath,
+			Type:        DiffElementNotFound,
+			SubsetValue: subsetElem,
+			Message:     fmt.Sprintf("occurs %d times in subset, %d matching elements in superset", occurrences, len(candidates[i])),
+		})
+	}
+
+	if opts.matches != nil {
+		for j, i := range owner {
+			if i >= 0 {
+				tryMatch(subset[i], superset[j], append(copyPath(path), spec.Index(i)), opts)
+			}
+		}
+	}
+
+	return isSubset, diffs
+}
+
+// assignElement finds a superset element for subset[i], moving earlier
+// assignments to other candidates when needed (augmenting path).
+func assignElement(i int, candidates [][]int, owner []int, seen []bool) bool {
+	for _, j := range candidates[i] {
+		if seen[j] {
+			continue
+		}
+		seen[j] = true
+		if owner[j] == -1 || assignElement(owner[j], candidates, owner, seen) {
+			owner[j] = i
+			return true
+		}
+	}
+	return false
+}
+
+// copyPath creates a copy of a NormalizedPath
+func copyPath(path spec.NormalizedPath) spec.NormalizedPath {
+	return append(spec.NormalizedPath{}, path...)
+}
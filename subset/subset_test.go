@@ -0,0 +1,1220 @@
+package subset
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestSubsetCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		wantSubset bool
+	}{
+		{
+			name:       "object subset",
+			subset:     map[string]interface{}{"a": float64(1)},
+			superset:   map[string]interface{}{"a": float64(1), "b": float64(2)},
+			wantSubset: true,
+		},
+		{
+			name:       "object not subset - missing key",
+			subset:     map[string]interface{}{"a": float64(1), "c": float64(3)},
+			superset:   map[string]interface{}{"a": float64(1), "b": float64(2)},
+			wantSubset: false,
+		},
+		{
+			name:       "array subset - set mode ignores order",
+			subset:     []interface{}{float64(2), float64(1)},
+			superset:   []interface{}{float64(1), float64(2), float64(3)},
+			wantSubset: true,
+		},
+		{
+			name:       "array not subset - element not found",
+			subset:     []interface{}{float64(1), float64(4)},
+			superset:   []interface{}{float64(1), float64(2), float64(3)},
+			wantSubset: false,
+		},
+		{
+			name:       "nested object subset",
+			subset:     map[string]interface{}{"user": map[string]interface{}{"name": "alice"}},
+			superset:   map[string]interface{}{"user": map[string]interface{}{"name": "alice", "age": float64(30)}},
+			wantSubset: true,
+		},
+		{
+			name:       "nested object not subset",
+			subset:     map[string]interface{}{"user": map[string]interface{}{"name": "alice", "email": "alice@example.com"}},
+			superset:   map[string]interface{}{"user": map[string]interface{}{"name": "alice"}},
+			wantSubset: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubset(tt.subset, tt.superset)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubset() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+}
+
+func TestOrderedArrayCheck(t *testing.T) {
+	opts := Options{ArrayOrder: ArrayOrdered}
+
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		wantSubset bool
+		wantPath   string
+	}{
+		{
+			name:       "same order",
+			subset:     []interface{}{float64(1), float64(2)},
+			superset:   []interface{}{float64(1), float64(2), float64(3)},
+			wantSubset: true,
+		},
+		{
+			name:       "different order",
+			subset:     []interface{}{float64(2), float64(1)},
+			superset:   []interface{}{float64(1), float64(2), float64(3)},
+			wantSubset: false,
+			wantPath:   "$[0]",
+		},
+		{
+			name:       "subset longer than superset",
+			subset:     []interface{}{float64(1), float64(2), float64(3)},
+			superset:   []interface{}{float64(1), float64(2)},
+			wantSubset: false,
+			wantPath:   "$[2]",
+		},
+		{
+			name:       "positional object subset",
+			subset:     []interface{}{map[string]interface{}{"a": float64(1)}},
+			superset:   []interface{}{map[string]interface{}{"a": float64(1), "b": float64(2)}},
+			wantSubset: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, opts)
+			if got != tt.wantSubset {
+				t.Fatalf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+			if tt.wantPath != "" && diffs[0].Path.String() != tt.wantPath {
+				t.Errorf("diff path = %s, want %s", diffs[0].Path, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestMultisetArrayCheck(t *testing.T) {
+	opts := Options{ArrayOrder: ArrayMultiset}
+
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		wantSubset bool
+	}{
+		{
+			name:       "duplicates matched by duplicates",
+			subset:     []interface{}{float64(1), float64(1)},
+			superset:   []interface{}{float64(1), float64(2), float64(1)},
+			wantSubset: true,
+		},
+		{
+			name:       "duplicate without counterpart",
+			subset:     []interface{}{float64(1), float64(1)},
+			superset:   []interface{}{float64(1), float64(2)},
+			wantSubset: false,
+		},
+		{
+			name: "greedy choice is revisited",
+			subset: []interface{}{
+				map[string]interface{}{"a": float64(1)},
+				map[string]interface{}{"a": float64(1), "b": float64(2)},
+			},
+			superset: []interface{}{
+				map[string]interface{}{"a": float64(1), "b": float64(2)},
+				map[string]interface{}{"a": float64(1)},
+			},
+			wantSubset: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+
+	_, diffs := CheckSubsetWithOptions([]interface{}{float64(1), float64(1)}, []interface{}{float64(1)}, opts)
+	if len(diffs) != 1 {
+		t.Fatalf("got %d diffs, want 1", len(diffs))
+	}
+	if diffs[0].Type != DiffElementNotFound || diffs[0].Path.String() != "$[1]" {
+		t.Errorf("unexpected diff: %+v", diffs[0])
+	}
+	if !strings.Contains(diffs[0].Message, "occurs 2 times") {
+		t.Errorf("message %q should contain occurrence count", diffs[0].Message)
+	}
+}
+
+func TestEpsilon(t *testing.T) {
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		epsilon    float64
+		wantSubset bool
+	}{
+		{
+			name:       "within epsilon",
+			subset:     float64(1.0000001),
+			superset:   float64(1.0),
+			epsilon:    1e-5,
+			wantSubset: true,
+		},
+		{
+			name:       "zero epsilon is exact",
+			subset:     float64(1.0000001),
+			superset:   float64(1.0),
+			epsilon:    0,
+			wantSubset: false,
+		},
+		{
+			name:       "numeric strings are not affected",
+			subset:     "1.0000001",
+			superset:   "1.0",
+			epsilon:    1e-5,
+			wantSubset: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, Options{Epsilon: tt.epsilon})
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+}
+
+func TestIgnoreCase(t *testing.T) {
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		opts       Options
+		wantSubset bool
+	}{
+		{
+			name:       "value case differs",
+			subset:     map[string]interface{}{"status": "ACTIVE"},
+			superset:   map[string]interface{}{"status": "active"},
+			wantSubset: false,
+		},
+		{
+			name:       "value case ignored",
+			subset:     map[string]interface{}{"status": "ACTIVE"},
+			superset:   map[string]interface{}{"status": "active"},
+			opts:       Options{IgnoreCase: true},
+			wantSubset: true,
+		},
+		{
+			name:       "value case does not affect keys",
+			subset:     map[string]interface{}{"Status": "active"},
+			superset:   map[string]interface{}{"status": "active"},
+			opts:       Options{IgnoreCase: true},
+			wantSubset: false,
+		},
+		{
+			name:       "key case ignored",
+			subset:     map[string]interface{}{"Status": "active"},
+			superset:   map[string]interface{}{"status": "active"},
+			opts:       Options{IgnoreKeyCase: true},
+			wantSubset: true,
+		},
+		{
+			name:       "key case does not affect values",
+			subset:     map[string]interface{}{"Status": "ACTIVE"},
+			superset:   map[string]interface{}{"status": "active"},
+			opts:       Options{IgnoreKeyCase: true},
+			wantSubset: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+}
+
+func TestTrimStrings(t *testing.T) {
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		opts       Options
+		wantSubset bool
+	}{
+		{
+			name:       "whitespace differs",
+			subset:     map[string]interface{}{"name": "  alice  "},
+			superset:   map[string]interface{}{"name": "alice"},
+			wantSubset: false,
+		},
+		{
+			name:       "whitespace trimmed",
+			subset:     map[string]interface{}{"name": "  alice  "},
+			superset:   map[string]interface{}{"name": "alice"},
+			opts:       Options{TrimStrings: true},
+			wantSubset: true,
+		},
+		{
+			name:       "superset side trimmed",
+			subset:     []interface{}{"alice"},
+			superset:   []interface{}{"bob", "alice\n"},
+			opts:       Options{TrimStrings: true},
+			wantSubset: true,
+		},
+		{
+			name:       "inner whitespace kept",
+			subset:     map[string]interface{}{"name": "alice  smith"},
+			superset:   map[string]interface{}{"name": "alice smith"},
+			opts:       Options{TrimStrings: true},
+			wantSubset: false,
+		},
+		{
+			name:       "combined with ignore case",
+			subset:     map[string]interface{}{"name": " Alice"},
+			superset:   map[string]interface{}{"name": "alice "},
+			opts:       Options{TrimStrings: true, IgnoreCase: true},
+			wantSubset: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+}
+
+func TestIgnoreKeyWhitespace(t *testing.T) {
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		opts       Options
+		wantSubset bool
+	}{
+		{
+			name:       "padded key differs by default",
+			subset:     map[string]interface{}{" name": "alice"},
+			superset:   map[string]interface{}{"name": "alice"},
+			wantSubset: false,
+		},
+		{
+			name:       "padded subset key",
+			subset:     map[string]interface{}{" name": "alice"},
+			superset:   map[string]interface{}{"name": "alice"},
+			opts:       Options{IgnoreKeyWhitespace: true},
+			wantSubset: true,
+		},
+		{
+			name:       "padded superset key",
+			subset:     map[string]interface{}{"user": map[string]interface{}{"id": float64(1)}},
+			superset:   map[string]interface{}{"user\t": map[string]interface{}{" id ": float64(1)}},
+			opts:       Options{IgnoreKeyWhitespace: true},
+			wantSubset: true,
+		},
+		{
+			name:       "inner whitespace kept",
+			subset:     map[string]interface{}{"user name": "alice"},
+			superset:   map[string]interface{}{"username": "alice"},
+			opts:       Options{IgnoreKeyWhitespace: true},
+			wantSubset: false,
+		},
+		{
+			name:       "values still compared",
+			subset:     map[string]interface{}{"name ": "alice"},
+			superset:   map[string]interface{}{"name": "bob"},
+			opts:       Options{IgnoreKeyWhitespace: true},
+			wantSubset: false,
+		},
+		{
+			name:       "combined with key case",
+			subset:     map[string]interface{}{" Name": "alice"},
+			superset:   map[string]interface{}{"name ": "alice"},
+			opts:       Options{IgnoreKeyWhitespace: true, IgnoreKeyCase: true},
+			wantSubset: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v; diffs: %+v", got, tt.wantSubset, diffs)
+			}
+		})
+	}
+}
+
+func TestCoerceBool(t *testing.T) {
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		opts       Options
+		wantSubset bool
+	}{
+		{"string and bool differ", "true", true, Options{}, false},
+		{"string subset coerced", "true", true, Options{CoerceBool: true}, true},
+		{"string superset coerced", false, "false", Options{CoerceBool: true}, true},
+		{"coerced values differ", "true", false, Options{CoerceBool: true}, false},
+		{"only exact spellings", "True", true, Options{CoerceBool: true}, false},
+		{"other strings untouched", "yes", true, Options{CoerceBool: true}, false},
+		{"numbers untouched", float64(1), true, Options{CoerceBool: true}, false},
+		{"in set arrays", []interface{}{"true"}, []interface{}{false, true}, Options{CoerceBool: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+}
+
+func TestEmptyEqualsNull(t *testing.T) {
+	opts := Options{EmptyEqualsNull: true}
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		opts       Options
+		wantSubset bool
+	}{
+		{"differ by default", "", nil, Options{}, false},
+		{"empty subset, null superset", map[string]interface{}{"a": ""}, map[string]interface{}{"a": nil}, opts, true},
+		{"null subset, empty superset", map[string]interface{}{"a": nil}, map[string]interface{}{"a": ""}, opts, true},
+		{"with ignore values", "", nil, Options{EmptyEqualsNull: true, IgnoreValues: true}, true},
+		{"other strings still differ from null", "x", nil, opts, false},
+		{"blank strings are not empty", " ", nil, opts, false},
+		{"missing keys still missing", map[string]interface{}{"a": ""}, map[string]interface{}{}, opts, false},
+		{"in set arrays", []interface{}{nil}, []interface{}{"x", ""}, opts, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _ := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+		})
+	}
+}
+
+func TestMaxDepth(t *testing.T) {
+	subset := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": float64(1)},
+		},
+		"x": "same",
+	}
+	superset := map[string]interface{}{
+		"a": map[string]interface{}{
+			"b": map[string]interface{}{"c": float64(2)},
+		},
+		"x": "different",
+	}
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantSubset bool
+		wantPaths  []string
+	}{
+		{
+			name:      "unlimited",
+			opts:      Options{},
+			wantPaths: []string{"$['a']['b']['c']", "$['x']"},
+		},
+		{
+			name:       "depth 0 checks top-level presence only",
+			opts:       Options{LimitDepth: true, MaxDepth: 0},
+			wantSubset: true,
+		},
+		{
+			name:      "depth 1 compares top-level values",
+			opts:      Options{LimitDepth: true, MaxDepth: 1},
+			wantPaths: []string{"$['x']"},
+		},
+		{
+			name:      "depth 3 reaches the leaf",
+			opts:      Options{LimitDepth: true, MaxDepth: 3},
+			wantPaths: []string{"$['a']['b']['c']", "$['x']"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diffs := CheckSubsetWithOptions(subset, superset, tt.opts)
+			if got != tt.wantSubset {
+				t.Fatalf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+			if len(diffs) != len(tt.wantPaths) {
+				t.Fatalf("got %d diffs, want %d: %+v", len(diffs), len(tt.wantPaths), diffs)
+			}
+			for i, want := range tt.wantPaths {
+				if diffs[i].Path.String() != want {
+					t.Errorf("diff %d path = %s, want %s", i, diffs[i].Path, want)
+				}
+			}
+		})
+	}
+
+	// Presence is still required at the boundary.
+	missing := map[string]interface{}{"y": float64(1)}
+	if ok, _ := CheckSubsetWithOptions(missing, superset, Options{LimitDepth: true, MaxDepth: 0}); ok {
+		t.Error("missing top-level key should fail at depth 0")
+	}
+}
+
+func TestArrayExactLength(t *testing.T) {
+	subset := []interface{}{float64(1), float64(2)}
+	superset := []interface{}{float64(1), float64(2), float64(3)}
+
+	if ok, _ := CheckSubset(subset, superset); !ok {
+		t.Fatal("shorter array should be a subset by default")
+	}
+
+	for _, order := range []ArrayOrder{ArraySet, ArrayOrdered, ArrayMultiset} {
+		opts := Options{ArrayOrder: order, ArrayExactLength: true}
+		ok, diffs := CheckSubsetWithOptions(subset, superset, opts)
+		if ok {
+			t.Errorf("order %d: CheckSubsetWithOptions() = true, want false", order)
+			continue
+		}
+		if len(diffs) != 1 || diffs[0].Type != DiffArrayLengthMismatch || diffs[0].Path.String() != "$" {
+			t.Errorf("order %d: unexpected diffs %+v", order, diffs)
+		}
+	}
+
+	// The length diff comes first and element diffs are still reported.
+	_, diffs := CheckSubsetWithOptions([]interface{}{float64(4)}, superset, Options{ArrayExactLength: true})
+	if len(diffs) != 2 || diffs[0].Type != DiffArrayLengthMismatch || diffs[1].Type != DiffElementNotFound {
+		t.Errorf("unexpected diffs %+v", diffs)
+	}
+
+	if ok, _ := CheckSubsetWithOptions([]interface{}{float64(3), float64(2), float64(1)}, superset, Options{ArrayExactLength: true}); !ok {
+		t.Error("same length set should pass")
+	}
+}
+
+func TestWildcardKey(t *testing.T) {
+	superset := map[string]interface{}{
+		"members": map[string]interface{}{
+			"alice": map[string]interface{}{"role": "user"},
+			"bob":   map[string]interface{}{"role": "admin", "team": "infra"},
+		},
+	}
+	opts := Options{EnableWildcard: true}
+
+	admin := map[string]interface{}{
+		"members": map[string]interface{}{"*": map[string]interface{}{"role": "admin"}},
+	}
+	if ok, diffs := CheckSubsetWithOptions(admin, superset, opts); !ok {
+		t.Errorf("wildcard should match bob, diffs: %+v", diffs)
+	}
+	if ok, _ := CheckSubset(admin, superset); ok {
+		t.Error("\"*\" should be a literal key without EnableWildcard")
+	}
+
+	owner := map[string]interface{}{
+		"members": map[string]interface{}{"*": map[string]interface{}{"role": "owner"}},
+	}
+	ok, diffs := CheckSubsetWithOptions(owner, superset, opts)
+	if ok {
+		t.Fatal("wildcard should not match any member")
+	}
+	if len(diffs) != 1 || diffs[0].Type != DiffElementNotFound || diffs[0].Path.String() != "$['members']['*']" {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+}
+
+func TestIgnoreValues(t *testing.T) {
+	opts := Options{IgnoreValues: true}
+
+	tests := []struct {
+		name       string
+		subset     interface{}
+		superset   interface{}
+		wantSubset bool
+		wantType   DiffType
+	}{
+		{
+			name:       "numbers with different values",
+			subset:     map[string]interface{}{"a": float64(1)},
+			superset:   map[string]interface{}{"a": float64(999)},
+			wantSubset: true,
+		},
+		{
+			name:       "nested structure",
+			subset:     map[string]interface{}{"user": map[string]interface{}{"name": "x", "tags": []interface{}{"a"}}},
+			superset:   map[string]interface{}{"user": map[string]interface{}{"name": "alice", "tags": []interface{}{"admin"}}},
+			wantSubset: true,
+		},
+		{
+			name:       "string against number",
+			subset:     map[string]interface{}{"a": "x"},
+			superset:   map[string]interface{}{"a": float64(1)},
+			wantSubset: false,
+			wantType:   DiffTypeMismatch,
+		},
+		{
+			name:       "missing key still fails",
+			subset:     map[string]interface{}{"b": float64(1)},
+			superset:   map[string]interface{}{"a": float64(1)},
+			wantSubset: false,
+			wantType:   DiffMissingKey,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, opts)
+			if got != tt.wantSubset {
+				t.Fatalf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantSubset)
+			}
+			if !tt.wantSubset && diffs[0].Type != tt.wantType {
+				t.Errorf("diff type = %v, want %v", diffs[0].Type, tt.wantType)
+			}
+		})
+	}
+
+	if ok, _ := CheckSubset(map[string]interface{}{"a": float64(1)}, map[string]interface{}{"a": float64(999)}); ok {
+		t.Error("values should be compared by default")
+	}
+}
+
+func TestShowExtra(t *testing.T) {
+	subset := map[string]interface{}{
+		"user": map[string]interface{}{"name": "alice"},
+	}
+	superset := map[string]interface{}{
+		"user": map[string]interface{}{"name": "alice", "age": float64(30)},
+		"id":   float64(1),
+	}
+
+	ok, diffs := CheckSubsetWithOptions(subset, superset, Options{ShowExtra: true})
+	if !ok {
+		t.Fatal("extra keys should not make the check fail")
+	}
+	want := []string{"$['user']['age']", "$['id']"}
+	if len(diffs) != len(want) {
+		t.Fatalf("got %d diffs, want %d: %+v", len(diffs), len(want), diffs)
+	}
+	for i, path := range want {
+		if diffs[i].Type != DiffExtraKey || diffs[i].Path.String() != path {
+			t.Errorf("diff %d = %v %s, want extra key %s", i, diffs[i].Type, diffs[i].Path, path)
+		}
+	}
+
+	if _, diffs := CheckSubset(subset, superset); len(diffs) != 0 {
+		t.Errorf("extra keys should only be reported with ShowExtra, got %+v", diffs)
+	}
+
+	// A real mismatch still fails and is reported alongside extras.
+	subset["user"].(map[string]interface{})["name"] = "bob"
+	ok, diffs = CheckSubsetWithOptions(subset, superset, Options{ShowExtra: true})
+	if ok || len(diffs) != 3 {
+		t.Errorf("CheckSubsetWithOptions() = %v with %d diffs, want false with 3", ok, len(diffs))
+	}
+}
+
+func TestCheckSubsetExplain(t *testing.T) {
+	subset := map[string]interface{}{
+		"name": "alice",
+		"tags": []interface{}{"b"},
+		"role": "admin",
+	}
+	superset := map[string]interface{}{
+		"name": "alice",
+		"tags": []interface{}{"a", "b"},
+		"role": "user",
+	}
+
+	ok, diffs, matches := CheckSubsetExplain(subset, superset, Options{})
+	if ok || len(diffs) != 1 {
+		t.Fatalf("CheckSubsetExplain() = %v with %d diffs, want false with 1", ok, len(diffs))
+	}
+	got := make([]string, len(matches))
+	for i, path := range matches {
+		got[i] = path.String()
+	}
+	want := []string{"$['name']", "$['tags'][0]"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("matches = %v, want %v", got, want)
+	}
+}
+
+func TestSetArrayClosestMatch(t *testing.T) {
+	subset := []interface{}{
+		map[string]interface{}{"id": float64(2), "name": "bob", "role": "admin"},
+	}
+	superset := []interface{}{
+		map[string]interface{}{"id": float64(1), "name": "alice", "role": "admin"},
+		map[string]interface{}{"id": float64(2), "name": "bob", "role": "user"},
+		"bob",
+	}
+
+	ok, diffs := CheckSubset(subset, superset)
+	if ok || len(diffs) != 1 {
+		t.Fatalf("CheckSubset() = %v with %d diffs, want false with 1", ok, len(diffs))
+	}
+	want := "closest match is superset index 1 (2 of 3 keys match)"
+	if diffs[0].Type != DiffElementNotFound || diffs[0].Message != want {
+		t.Errorf("diff = %v %q, want element not found with %q", diffs[0].Type, diffs[0].Message, want)
+	}
+
+	// Nothing in common: no closest match is reported.
+	_, diffs = CheckSubset(subset, []interface{}{map[string]interface{}{"other": true}})
+	if len(diffs) != 1 || diffs[0].Message != "" {
+		t.Errorf("diffs = %+v, want one diff without a message", diffs)
+	}
+
+	// Scalars have no partial matches.
+	_, diffs = CheckSubset([]interface{}{"x"}, []interface{}{"y"})
+	if len(diffs) != 1 || diffs[0].Message != "" {
+		t.Errorf("diffs = %+v, want one diff without a message", diffs)
+	}
+}
+
+func TestSetArrayBestCandidate(t *testing.T) {
+	subset := []interface{}{
+		map[string]interface{}{
+			"name":    "alice",
+			"address": map[string]interface{}{"city": "Tokyo", "zip": "100-0001", "country": "JP"},
+		},
+	}
+	superset := []interface{}{
+		map[string]interface{}{"name": "alice", "address": map[string]interface{}{"city": "Osaka"}},
+		map[string]interface{}{"name": "alicia", "address": map[string]interface{}{"city": "Tokyo", "zip": "100-0001", "country": "US"}},
+		map[string]interface{}{"name": "bob"},
+	}
+
+	ok, diffs := CheckSubset(subset, superset)
+	if ok || len(diffs) != 1 {
+		t.Fatalf("CheckSubset() = %v with %d diffs, want false with 1", ok, len(diffs))
+	}
+	// Index 1 matches two nested leaves, index 0 only the name.
+	candidate := diffs[0].Candidate
+	if candidate == nil || candidate.Index != 1 {
+		t.Fatalf("candidate = %+v, want superset index 1", candidate)
+	}
+	if !reflect.DeepEqual(candidate.Value, superset[1]) {
+		t.Errorf("candidate value = %v, want %v", candidate.Value, superset[1])
+	}
+	var paths []string
+	for _, d := range candidate.Diffs {
+		paths = append(paths, d.Path.String())
+	}
+	want := []string{"$[0]['address']['country']", "$[0]['name']"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("candidate diff paths = %v, want %v", paths, want)
+	}
+
+	// Nested differences and missing keys are all kept.
+	_, diffs = CheckSubset(subset, superset[:1])
+	candidate = diffs[0].Candidate
+	if candidate == nil || candidate.Index != 0 {
+		t.Fatalf("candidate = %+v, want superset index 0", candidate)
+	}
+	var types []DiffType
+	for _, d := range candidate.Diffs {
+		types = append(types, d.Type)
+	}
+	wantTypes := []DiffType{DiffValueMismatch, DiffMissingKey, DiffMissingKey}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Errorf("candidate diff types = %v, want %v", types, wantTypes)
+	}
+
+	// Nothing in common: no candidate.
+	_, diffs = CheckSubset(subset, superset[2:])
+	if len(diffs) != 1 || diffs[0].Candidate != nil {
+		t.Errorf("diffs = %+v, want one without a candidate", diffs)
+	}
+}
+
+func TestMixedArrayCandidate(t *testing.T) {
+	var subset, superset interface{}
+	if err := json.Unmarshal([]byte(`[1, "a", {"id": 7, "tags": ["x"], "meta": {"ok": true}}, null]`), &subset); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`["a", [1], {"id": 8}, null, {"id": 7, "tags": ["y"], "meta": {"ok": false}}, 1, true]`), &superset); err != nil {
+		t.Fatal(err)
+	}
+
+	ok, diffs := CheckSubset(subset, superset)
+	if ok || len(diffs) != 1 {
+		t.Fatalf("CheckSubset() = %v, %+v, want one diff", ok, diffs)
+	}
+	d := diffs[0]
+	if d.Path.String() != "$[2]" || d.Type != DiffElementNotFound {
+		t.Fatalf("diff = %s %v, want element_not_found at $[2]", d.Path, d.Type)
+	}
+	if d.Candidate == nil || d.Candidate.Index != 4 {
+		t.Fatalf("candidate = %+v, want superset index 4", d.Candidate)
+	}
+	var got []string
+	for _, cd := range d.Candidate.Diffs {
+		got = append(got, cd.Path.String()+" "+cd.Type.String())
+	}
+	want := []string{"$[2]['meta']['ok'] value_mismatch", "$[2]['tags'][0] element_not_found"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("candidate diffs = %v, want %v", got, want)
+	}
+
+	output := FormatDiffOutput(subset, diffs)
+	for _, line := range []string{
+		`-  { (closest match is superset index 4)`,
+		`-      "ok": true (closest match: false)`,
+	} {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("output missing %q:\n%s", line, output)
+		}
+	}
+}
+
+func TestNestedSetArrayCandidates(t *testing.T) {
+	// Each level wraps the one below in a set array; the superset offers
+	// three near misses per level. Closest matches are only searched for
+	// along the reported diff, not in every trial match, so this stays fast.
+	var subset, superset interface{} = map[string]interface{}{"v": 1.0}, map[string]interface{}{"v": 2.0}
+	const depth = 10
+	for i := 0; i < depth; i++ {
+		id := float64(i)
+		subset = []interface{}{map[string]interface{}{"id": id, "x": subset}}
+		superset = []interface{}{
+			map[string]interface{}{"id": id, "x": superset},
+			map[string]interface{}{"id": id + 1, "x": superset},
+			map[string]interface{}{"y": superset},
+		}
+	}
+
+	ok, diffs := CheckSubset(subset, superset)
+	if ok || len(diffs) != 1 {
+		t.Fatalf("CheckSubset() = %v with %d diffs, want false with 1", ok, len(diffs))
+	}
+	levels := 0
+	for d := diffs[0]; d.Candidate != nil; levels++ {
+		if len(d.Candidate.Diffs) != 1 {
+			t.Fatalf("candidate diffs at level %d = %+v, want 1", levels, d.Candidate.Diffs)
+		}
+		d = d.Candidate.Diffs[0]
+	}
+	if levels != depth {
+		t.Errorf("nested candidates = %d levels, want %d", levels, depth)
+	}
+}
+
+func TestFirstKeys(t *testing.T) {
+	subset := map[string]interface{}{
+		"a": float64(1),
+		"b": map[string]interface{}{"x": float64(1), "y": float64(2), "z": float64(3)},
+		"c": "missing",
+	}
+	superset := map[string]interface{}{
+		"a": float64(1),
+		"b": map[string]interface{}{"x": float64(1), "y": float64(2), "z": float64(0)},
+	}
+
+	tests := []struct {
+		firstKeys int
+		wantPaths []string
+	}{
+		{0, []string{"$['b']['z']", "$['c']"}},
+		{1, nil},
+		{2, nil},
+		{3, []string{"$['b']['z']", "$['c']"}},
+	}
+	for _, tt := range tests {
+		_, diffs := CheckSubsetWithOptions(subset, superset, Options{FirstKeys: tt.firstKeys})
+		var paths []string
+		for _, d := range diffs {
+			paths = append(paths, d.Path.String())
+		}
+		if !reflect.DeepEqual(paths, tt.wantPaths) {
+			t.Errorf("FirstKeys %d: diff paths = %v, want %v", tt.firstKeys, paths, tt.wantPaths)
+		}
+	}
+
+	// Only $.a and $.b with its keys $.b.x and $.b.y are visited.
+	stats := &Stats{}
+	CheckSubsetWithOptions(subset, superset, Options{FirstKeys: 2, Stats: stats})
+	if stats.ObjectKeys != 4 {
+		t.Errorf("compared %d keys, want 4", stats.ObjectKeys)
+	}
+}
+
+func TestArrayElementsIgnoreFields(t *testing.T) {
+	subset := map[string]interface{}{"events": []interface{}{
+		map[string]interface{}{"type": "login", "ts": "2024-05-01T10:00:00Z"},
+		map[string]interface{}{"type": "logout", "ts": "2024-05-01T11:00:00Z"},
+	}}
+	superset := map[string]interface{}{"events": []interface{}{
+		map[string]interface{}{"type": "logout", "ts": "2024-06-02T09:30:00Z", "user": "alice"},
+		map[string]interface{}{"type": "login", "ts": "2024-06-02T08:00:00Z", "user": "alice"},
+	}}
+
+	if ok, _ := CheckSubset(subset, superset); ok {
+		t.Fatal("elements with different timestamps should not match without ignoring ts")
+	}
+
+	for _, pattern := range []string{"ts", "$.events[*].ts"} {
+		p, err := ParsePathPattern(pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, order := range []ArrayOrder{ArraySet, ArrayMultiset} {
+			opts := Options{Ignore: []PathPattern{p}, ArrayOrder: order}
+			if ok, diffs := CheckSubsetWithOptions(subset, superset, opts); !ok {
+				t.Errorf("ignore %q, order %d: unexpected diffs: %+v", pattern, order, diffs)
+			}
+		}
+	}
+
+	// The closest match leaves ignored fields out of the count.
+	p, _ := ParsePathPattern("ts")
+	subset["events"] = []interface{}{map[string]interface{}{"type": "signup", "user": "alice", "ts": "x"}}
+	_, diffs := CheckSubsetWithOptions(subset, superset, Options{Ignore: []PathPattern{p}})
+	want := "closest match is superset index 0 (1 of 2 keys match)"
+	if len(diffs) != 1 || diffs[0].Message != want {
+		t.Errorf("diffs = %+v, want one with message %q", diffs, want)
+	}
+}
+
+func TestNullMeansOptional(t *testing.T) {
+	subset := map[string]interface{}{"name": "alice", "middleName": nil}
+	opts := Options{NullMeansOptional: true}
+
+	tests := []struct {
+		name     string
+		superset map[string]interface{}
+		want     bool
+	}{
+		{"present null", map[string]interface{}{"name": "alice", "middleName": nil}, true},
+		{"absent", map[string]interface{}{"name": "alice"}, true},
+		{"present non-null", map[string]interface{}{"name": "alice", "middleName": "b"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, diffs := CheckSubsetWithOptions(subset, tt.superset, opts); got != tt.want {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v; diffs: %+v", got, tt.want, diffs)
+			}
+		})
+	}
+
+	if ok, diffs := CheckSubset(subset, map[string]interface{}{"name": "alice"}); ok || diffs[0].Type != DiffMissingKey {
+		t.Errorf("a missing key should still be reported by default, got %v %+v", ok, diffs)
+	}
+}
+
+func TestIgnoreNullValues(t *testing.T) {
+	subset := map[string]interface{}{
+		"name":  "alice",
+		"id":    nil,
+		"items": []interface{}{map[string]interface{}{"sku": "a", "price": nil}},
+	}
+	opts := Options{IgnoreNullValues: true}
+
+	tests := []struct {
+		name     string
+		superset map[string]interface{}
+		want     bool
+	}{
+		{"placeholder matches a string", map[string]interface{}{
+			"name": "alice", "id": "u-123",
+			"items": []interface{}{map[string]interface{}{"sku": "a", "price": float64(5)}},
+		}, true},
+		{"placeholder matches a missing key", map[string]interface{}{
+			"name":  "alice",
+			"items": []interface{}{map[string]interface{}{"sku": "a"}},
+		}, true},
+		{"other keys are still compared", map[string]interface{}{
+			"name": "bob", "id": "u-123",
+			"items": []interface{}{map[string]interface{}{"sku": "a"}},
+		}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, diffs := CheckSubsetWithOptions(subset, tt.superset, opts); got != tt.want {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v; diffs: %+v", got, tt.want, diffs)
+			}
+		})
+	}
+
+	// Without the option, null is a value like any other.
+	superset := tests[0].superset
+	if ok, diffs := CheckSubset(subset, superset); ok || len(diffs) != 2 {
+		t.Errorf("CheckSubset() = %v with diffs %+v, want the two null mismatches", ok, diffs)
+	}
+	// Null elements of arrays are not keys and are still compared.
+	if ok, _ := CheckSubsetWithOptions([]interface{}{nil}, []interface{}{"x"}, opts); ok {
+		t.Error("a null array element should not be skipped")
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	subset := map[string]interface{}{
+		"a":     float64(1),
+		"b":     "x",
+		"c":     map[string]interface{}{"d": true, "e": false},
+		"tags":  []interface{}{"p", "q"},
+		"items": []interface{}{float64(1), float64(2)},
+	}
+	superset := map[string]interface{}{
+		"a":     float64(2),
+		"b":     "y",
+		"c":     map[string]interface{}{"d": false},
+		"tags":  []interface{}{"z"},
+		"items": []interface{}{float64(3)},
+	}
+
+	_, all := CheckSubset(subset, superset)
+	if len(all) < 5 {
+		t.Fatalf("expected several diffs in normal mode, got %+v", all)
+	}
+
+	for _, order := range []ArrayOrder{ArraySet, ArrayOrdered, ArrayMultiset} {
+		opts := Options{FailFast: true, ArrayOrder: order, ArrayExactLength: true, RequiredKeys: []string{"id"}}
+		ok, diffs := CheckSubsetWithOptions(subset, superset, opts)
+		if ok || len(diffs) != 1 {
+			t.Errorf("order %d: CheckSubsetWithOptions() = %v with %d diffs, want false with 1: %+v", order, ok, len(diffs), diffs)
+		}
+	}
+
+	// The first failure in a nested array stops the whole comparison.
+	ok, diffs := CheckSubsetWithOptions(map[string]interface{}{"items": []interface{}{float64(1), float64(2)}}, map[string]interface{}{"items": []interface{}{}}, Options{FailFast: true})
+	if ok || len(diffs) != 1 || diffs[0].Path.String() != "$['items'][0]" {
+		t.Errorf("diffs = %+v, want only $['items'][0]", diffs)
+	}
+}
+
+func TestIntersection(t *testing.T) {
+	tests := []struct {
+		name             string
+		subset           map[string]interface{}
+		superset         map[string]interface{}
+		wantDefault      bool
+		wantIntersection bool
+	}{
+		{
+			name:             "keys only in subset are skipped",
+			subset:           map[string]interface{}{"a": float64(1), "onlySubset": true},
+			superset:         map[string]interface{}{"a": float64(1), "onlySuperset": true},
+			wantDefault:      false,
+			wantIntersection: true,
+		},
+		{
+			name:             "shared key mismatch still fails",
+			subset:           map[string]interface{}{"a": float64(1), "onlySubset": true},
+			superset:         map[string]interface{}{"a": float64(2)},
+			wantDefault:      false,
+			wantIntersection: false,
+		},
+		{
+			name:             "nested objects",
+			subset:           map[string]interface{}{"user": map[string]interface{}{"name": "alice", "nick": "al"}},
+			superset:         map[string]interface{}{"user": map[string]interface{}{"name": "alice"}},
+			wantDefault:      false,
+			wantIntersection: true,
+		},
+		{
+			name:             "no shared keys",
+			subset:           map[string]interface{}{"x": float64(1)},
+			superset:         map[string]interface{}{"y": float64(2)},
+			wantDefault:      false,
+			wantIntersection: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, _ := CheckSubset(tt.subset, tt.superset); got != tt.wantDefault {
+				t.Errorf("CheckSubset() = %v, want %v", got, tt.wantDefault)
+			}
+			got, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, Options{Intersection: true})
+			if got != tt.wantIntersection {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v", got, tt.wantIntersection)
+			}
+			for _, d := range diffs {
+				if d.Type == DiffMissingKey {
+					t.Errorf("intersection mode reported a missing key: %+v", d)
+				}
+			}
+		})
+	}
+}
+
+// branchDocs builds a subset and superset with n top-level branches, every
+// third of which has a mismatch
+func branchDocs(n int) (map[string]interface{}, map[string]interface{}) {
+	subset := make(map[string]interface{}, n)
+	superset := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key := fmt.Sprintf("branch%03d", i)
+		var subItems, superItems []interface{}
+		for j := 0; j < 20; j++ {
+			subItems = append(subItems, map[string]interface{}{"id": float64(j), "name": fmt.Sprintf("item-%d", j)})
+			superItems = append(superItems, map[string]interface{}{"id": float64(j), "name": fmt.Sprintf("item-%d", j), "extra": true})
+		}
+		if i%3 == 0 {
+			subItems = append(subItems, map[string]interface{}{"id": float64(99)})
+		}
+		subset[key] = map[string]interface{}{"items": subItems, "label": key}
+		superset[key] = map[string]interface{}{"items": superItems, "label": key, "other": float64(i)}
+	}
+	subset["missing"] = "x"
+	return subset, superset
+}
+
+func TestParallelMatchesSequential(t *testing.T) {
+	subset, superset := branchDocs(30)
+
+	var seqStats, parStats Stats
+	seqOK, seqDiffs, seqMatches := CheckSubsetExplain(subset, superset, Options{ShowExtra: true, Stats: &seqStats})
+	parOK, parDiffs, parMatches := CheckSubsetExplain(subset, superset, Options{ShowExtra: true, Stats: &parStats, Parallel: 4})
+
+	if seqOK != parOK || !reflect.DeepEqual(seqDiffs, parDiffs) {
+		t.Errorf("parallel result differs:\nsequential %v %+v\nparallel   %v %+v", seqOK, seqDiffs, parOK, parDiffs)
+	}
+	if !reflect.DeepEqual(seqMatches, parMatches) {
+		t.Errorf("parallel matches differ: %d vs %d paths", len(seqMatches), len(parMatches))
+	}
+	if seqStats != parStats {
+		t.Errorf("parallel stats = %+v, want %+v", parStats, seqStats)
+	}
+	if seqOK || len(seqDiffs) == 0 {
+		t.Fatal("the test documents should produce diffs")
+	}
+}
+
+func TestOptionCombinations(t *testing.T) {
+	obj := func(kv ...interface{}) map[string]interface{} {
+		m := make(map[string]interface{})
+		for i := 0; i < len(kv); i += 2 {
+			m[kv[i].(string)] = kv[i+1]
+		}
+		return m
+	}
+	arr := func(elems ...interface{}) []interface{} { return elems }
+
+	tests := []struct {
+		name      string
+		subset    interface{}
+		superset  interface{}
+		opts      Options
+		wantOK    bool
+		wantDiffs int
+	}{
+		{"ignore case in ordered arrays", arr("A", "b"), arr("a", "B", "c"), Options{IgnoreCase: true, ArrayOrder: ArrayOrdered}, true, 0},
+		{"ignore case in multiset arrays counts duplicates", arr("A", "a"), arr("a"), Options{IgnoreCase: true, ArrayOrder: ArrayMultiset}, false, 1},
+		{"epsilon in multiset arrays", arr(1.0, 1.0), arr(1.05, 0.98), Options{Epsilon: 0.1, ArrayOrder: ArrayMultiset}, true, 0},
+		{"epsilon with exact length", arr(1.0), arr(1.05, 2.0), Options{Epsilon: 0.1, ArrayExactLength: true}, false, 1},
+		{"key case with null means optional", obj("Name", "a", "Middle", nil), obj("name", "a"), Options{IgnoreKeyCase: true, NullMeansOptional: true}, true, 0},
+		{"trim and ignore case", obj("name", " Alice "), obj("name", "alice"), Options{TrimStrings: true, IgnoreCase: true}, true, 0},
+		{"trim without ignore case", obj("name", " Alice "), obj("name", "alice"), Options{TrimStrings: true}, false, 1},
+		{"array key with epsilon", obj("items", arr(obj("id", 1.0, "price", 1.0))), obj("items", arr(obj("id", 1.0, "price", 1.04))), Options{ArrayKey: "id", Epsilon: 0.05}, true, 0},
+		{"wildcard with ignore case", obj("*", "ALICE"), obj("x", "alice"), Options{EnableWildcard: true, IgnoreCase: true}, true, 0},
+		{"intersection with show extra", obj("a", 1.0, "b", 2.0), obj("a", 1.0, "c", 3.0), Options{Intersection: true, ShowExtra: true}, true, 1},
+		{"max depth with ignore values", obj("user", obj("id", "x", "tags", arr(1.0))), obj("user", obj("id", "y", "tags", "none")), Options{LimitDepth: true, MaxDepth: 1, IgnoreValues: true}, true, 0},
+		{"fail fast with ignore", obj("a", 1.0, "b", 2.0, "c", 3.0), obj("a", 0.0, "b", 0.0, "c", 0.0), Options{FailFast: true, Ignore: mustPatterns(t, "a")}, false, 1},
+		{"coerce bool with ignore null values", obj("on", "true", "id", nil), obj("on", true), Options{CoerceBool: true, IgnoreNullValues: true}, true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ok, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts)
+			if ok != tt.wantOK || len(diffs) != tt.wantDiffs {
+				t.Errorf("CheckSubsetWithOptions() = %v with %d diffs, want %v with %d; diffs: %+v", ok, len(diffs), tt.wantOK, tt.wantDiffs, diffs)
+			}
+		})
+	}
+}
+
+func BenchmarkCheckSequential(b *testing.B) {
+	subset, superset := branchDocs(200)
+	for i := 0; i < b.N; i++ {
+		CheckSubset(subset, superset)
+	}
+}
+
+func BenchmarkCheckParallel(b *testing.B) {
+	subset, superset := branchDocs(200)
+	opts := Options{Parallel: runtime.GOMAXPROCS(0)}
+	for i := 0; i < b.N; i++ {
+		CheckSubsetWithOptions(subset, superset, opts)
+	}
+}
+
+func TestAllowTypeWidening(t *testing.T) {
+	widen := Options{AllowTypeWidening: true}
+	tests := []struct {
+		name     string
+		subset   interface{}
+		superset interface{}
+		opts     Options
+		want     bool
+	}{
+		{"string in one-element array", "admin", []interface{}{"admin"}, widen, true},
+		{"number in one-element array", float64(1), []interface{}{json.Number("1.0")}, widen, true},
+		{"null in one-element array", nil, []interface{}{nil}, widen, true},
+		{"different value", "admin", []interface{}{"dev"}, widen, false},
+		{"two elements", "admin", []interface{}{"admin", "dev"}, widen, false},
+		{"empty array", "admin", []interface{}{}, widen, false},
+		{"only one level", "admin", []interface{}{[]interface{}{"admin"}}, widen, false},
+		{"objects are not widened", map[string]interface{}{"a": 1.0}, []interface{}{map[string]interface{}{"a": 1.0}}, widen, false},
+		{"nested key", map[string]interface{}{"role": "admin"}, map[string]interface{}{"role": []interface{}{"admin"}}, widen, true},
+		{"disabled by default", "admin", []interface{}{"admin"}, Options{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, diffs := CheckSubsetWithOptions(tt.subset, tt.superset, tt.opts); got != tt.want {
+				t.Errorf("CheckSubsetWithOptions() = %v, want %v; diffs: %+v", got, tt.want, diffs)
+			}
+		})
+	}
+
+	_, diffs := CheckSubsetWithOptions(map[string]interface{}{"role": "admin"}, map[string]interface{}{"role": []interface{}{"dev"}}, widen)
+	if len(diffs) != 1 || diffs[0].Path.String() != "$['role']" || diffs[0].SupersetValue != "dev" {
+		t.Errorf("diffs = %+v, want a value mismatch against the element", diffs)
+	}
+}
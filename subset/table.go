@@ -0,0 +1,34 @@
+package subset
+
+import (
+	"strings"
+	"text/tabwriter"
+)
+
+// FormatDiffTable renders one row per difference with aligned Path, Type,
+// Subset and Superset columns. Values are truncated to opts.ValueWidth; a
+// value that does not exist on one side, such as the superset value of a
+// missing key, is shown as "-". Paths are written in opts.PathStyle.
+func FormatDiffTable(diffs []Diff, opts FormatOptions) string {
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	w.Write([]byte("PATH\tTYPE\tSUBSET\tSUPERSET\n"))
+	for _, d := range diffs {
+		subsetValue := formatValue(d.SubsetValue, opts.ValueWidth)
+		supersetValue := formatValue(d.SupersetValue, opts.ValueWidth)
+		switch d.Type {
+		case DiffMissingKey, DiffElementNotFound:
+			supersetValue = "-"
+		case DiffExtraKey:
+			subsetValue = "-"
+		}
+		w.Write([]byte(strings.Join([]string{FormatPath(d.Path, opts.PathStyle), d.Type.String(), tableCell(subsetValue), tableCell(supersetValue)}, "\t") + "\n"))
+	}
+	w.Flush()
+	return sb.String()
+}
+
+// tableCell keeps tabs and newlines in a value from breaking the layout
+func tableCell(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ").Replace(s)
+}
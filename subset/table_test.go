@@ -0,0 +1,45 @@
+package subset
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatDiffTableGolden(t *testing.T) {
+	var sub, super interface{}
+	if err := json.Unmarshal([]byte(`{
+		"name": "myapp", "license": "MIT",
+		"description": "A very long description that will not fit in the column",
+		"tags": ["x", "z"],
+		"user": {"id": 1, "role": "admin"}
+	}`), &sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{
+		"name": "other",
+		"description": "Short",
+		"tags": ["x"],
+		"user": {"id": "1", "role": "admin", "active": true}
+	}`), &super); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diffs := CheckSubsetWithOptions(sub, super, Options{ShowExtra: true})
+	got := FormatDiffTable(diffs, FormatOptions{ValueWidth: 20})
+
+	want, err := os.ReadFile(filepath.Join("testdata", "table.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("FormatDiffTable() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatDiffTableEmpty(t *testing.T) {
+	if got, want := FormatDiffTable(nil, FormatOptions{}), "PATH  TYPE  SUBSET  SUPERSET\n"; got != want {
+		t.Errorf("FormatDiffTable(nil) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package subset
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// FormatDiffTAP renders the result of a check as a TAP version 13 stream
+// with a single test point named by description. Differences follow as a
+// YAML diagnostic block; values are written as JSON, which YAML reads as
+// flow values. Missing keys and elements have no superset value, extra
+// keys no subset value.
+func FormatDiffTAP(ok bool, description string, diffs []Diff) string {
+	return FormatDiffTAPWithOptions(ok, description, diffs, FormatOptions{})
+}
+
+// FormatDiffTAPWithOptions is FormatDiffTAP with paths written in
+// opts.PathStyle. Other options do not apply.
+func FormatDiffTAPWithOptions(ok bool, description string, diffs []Diff, opts FormatOptions) string {
+	var sb strings.Builder
+	sb.WriteString("TAP version 13\n1..1\n")
+	if !ok {
+		sb.WriteString("not ")
+	}
+	fmt.Fprintf(&sb, "ok 1 - %s\n", escapeTAPDescription(description))
+	if len(diffs) == 0 {
+		return sb.String()
+	}
+
+	sb.WriteString("  ---\n  diffs:\n")
+	for _, d := range diffs {
+		fmt.Fprintf(&sb, "    - path: %s\n", tapValue(FormatPath(d.Path, opts.PathStyle)))
+		fmt.Fprintf(&sb, "      type: %s\n", d.Type)
+		if d.Type != DiffExtraKey {
+			fmt.Fprintf(&sb, "      subset: %s\n", tapValue(d.SubsetValue))
+		}
+		if d.Type != DiffMissingKey && d.Type != DiffElementNotFound {
+			fmt.Fprintf(&sb, "      superset: %s\n", tapValue(d.SupersetValue))
+		}
+		fmt.Fprintf(&sb, "      message: %s\n", tapValue(describeDiff(d)))
+	}
+	sb.WriteString("  ...\n")
+	return sb.String()
+}
+
+// tapValue renders a value on one line for a YAML diagnostic
+func tapValue(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return tapValue(formatValue(v, DefaultValueWidth))
+	}
+	return string(data)
+}
+
+// escapeTAPDescription keeps a description on one line and stops a "#"
+// from starting a directive such as "# SKIP"
+func escapeTAPDescription(s string) string {
+	return strings.NewReplacer("\\", "\\\\", "#", "\\#", "\n", " ", "\r", " ").Replace(s)
+}
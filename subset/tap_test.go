@@ -0,0 +1,65 @@
+package subset
+
+import (
+	"testing"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+func TestFormatDiffTAP(t *testing.T) {
+	tests := []struct {
+		name        string
+		ok          bool
+		description string
+		diffs       []Diff
+		want        string
+	}{
+		{
+			name:        "pass",
+			ok:          true,
+			description: "a.json is a subset of b.json",
+			want:        "TAP version 13\n1..1\nok 1 - a.json is a subset of b.json\n",
+		},
+		{
+			name:        "fail",
+			description: "a.json is a subset of b.json",
+			diffs: []Diff{
+				{Path: spec.Normalized(spec.Name("name")), Type: DiffValueMismatch, SubsetValue: "alice", SupersetValue: "bob"},
+				{Path: spec.Normalized(spec.Name("tags"), spec.Index(0)), Type: DiffElementNotFound, SubsetValue: map[string]interface{}{"id": float64(1)}},
+				{Path: spec.Normalized(spec.Name("id")), Type: DiffExtraKey, SupersetValue: nil},
+			},
+			want: `TAP version 13
+1..1
+not ok 1 - a.json is a subset of b.json
+  ---
+  diffs:
+    - path: "$['name']"
+      type: value_mismatch
+      subset: "alice"
+      superset: "bob"
+      message: "want \"alice\", got \"bob\""
+    - path: "$['tags'][0]"
+      type: element_not_found
+      subset: {"id":1}
+      message: "no superset element matches {\"id\":1}"
+    - path: "$['id']"
+      type: extra_key
+      superset: null
+      message: "only in superset: null"
+  ...
+`,
+		},
+		{
+			name:        "description escaped",
+			description: "case #1\nsecond line",
+			want:        "TAP version 13\n1..1\nnot ok 1 - case \\#1 second line\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatDiffTAP(tt.ok, tt.description, tt.diffs); got != tt.want {
+				t.Errorf("FormatDiffTAP() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
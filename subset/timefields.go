@@ -0,0 +1,58 @@
+package subset
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// checkTime compares strings at Options.TimeFields locations as RFC 3339
+// times, so the same instant matches whatever its precision or offset.
+// handled is false for other locations and for non-string subset values.
+func checkTime(subset, superset interface{}, path spec.NormalizedPath, opts Options) (ok bool, diffs []Diff, handled bool) {
+	subsetStr, isString := subset.(string)
+	if !isString || !opts.timeFields.contains(path) {
+		return false, nil, false
+	}
+	mismatch := func(format string, args ...interface{}) []Diff {
+		return []Diff{{
+			Path:          copyPath(path),
+			Type:          DiffValueMismatch,
+			SubsetValue:   subset,
+			SupersetValue: superset,
+			Message:       fmt.Sprintf(format, args...),
+		}}
+	}
+
+	want, err := time.Parse(time.RFC3339Nano, subsetStr)
+	if err != nil {
+		return false, mismatch("subset value is not an RFC 3339 time: %v", err), true
+	}
+	supersetStr, isString := superset.(string)
+	if !isString {
+		return false, []Diff{{Path: copyPath(path), Type: DiffTypeMismatch, SubsetValue: subset, SupersetValue: superset}}, true
+	}
+	got, err := time.Parse(time.RFC3339Nano, supersetStr)
+	if err != nil {
+		return false, mismatch("superset value is not an RFC 3339 time: %v", err), true
+	}
+	if !got.Equal(want) {
+		return false, mismatch("superset time is %v %s", absDuration(got.Sub(want)), laterOrEarlier(got, want)), true
+	}
+	return true, nil, true
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+func laterOrEarlier(got, want time.Time) string {
+	if got.After(want) {
+		return "later"
+	}
+	return "earlier"
+}
@@ -0,0 +1,62 @@
+package subset
+
+import "testing"
+
+func TestTimeFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		patterns    []string
+		subset      interface{}
+		superset    interface{}
+		wantOK      bool
+		wantType    DiffType
+		wantMessage string
+	}{
+		{"milliseconds and offset", []string{"createdAt"}, "2020-01-01T00:00:00Z", "2020-01-01T00:00:00.000+00:00", true, 0, ""},
+		{"other timezone", []string{"$.createdAt"}, "2020-01-01T09:00:00+09:00", "2020-01-01T00:00:00Z", true, 0, ""},
+		{"compared as strings without the option", nil, "2020-01-01T00:00:00Z", "2020-01-01T00:00:00.000+00:00", false, DiffValueMismatch, ""},
+		{"different instant", []string{"createdAt"}, "2020-01-01T00:00:00Z", "2020-01-01T01:30:00+00:00", false, DiffValueMismatch, "superset time is 1h30m0s later"},
+		{"superset not a time", []string{"createdAt"}, "2020-01-01T00:00:00Z", "yesterday", false, DiffValueMismatch,
+			`superset value is not an RFC 3339 time: parsing time "yesterday" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "yesterday" as "2006"`},
+		{"subset not a time", []string{"createdAt"}, "2020-01-01", "2020-01-01T00:00:00Z", false, DiffValueMismatch,
+			`subset value is not an RFC 3339 time: parsing time "2020-01-01" as "2006-01-02T15:04:05.999999999Z07:00": cannot parse "" as "T"`},
+		{"superset not a string", []string{"createdAt"}, "2020-01-01T00:00:00Z", float64(1577836800), false, DiffTypeMismatch, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts Options
+			for _, s := range tt.patterns {
+				p, err := ParsePathPattern(s)
+				if err != nil {
+					t.Fatal(err)
+				}
+				opts.TimeFields = append(opts.TimeFields, p)
+			}
+			subset := map[string]interface{}{"createdAt": tt.subset}
+			superset := map[string]interface{}{"createdAt": tt.superset}
+
+			ok, diffs := CheckSubsetWithOptions(subset, superset, opts)
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v; diffs: %+v", ok, tt.wantOK, diffs)
+			}
+			if ok {
+				return
+			}
+			if len(diffs) != 1 || diffs[0].Type != tt.wantType || diffs[0].Message != tt.wantMessage {
+				t.Errorf("diffs = %+v, want one %v with message %q", diffs, tt.wantType, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestTimeFieldsArrayElements(t *testing.T) {
+	p, _ := ParsePathPattern("$.times[*]")
+	opts := Options{TimeFields: []PathPattern{p}}
+	subset := map[string]interface{}{"times": []interface{}{"2020-01-01T00:00:00Z"}}
+	superset := map[string]interface{}{"times": []interface{}{"2019-12-31T19:00:00-05:00"}}
+
+	if ok, diffs := CheckSubsetWithOptions(subset, superset, opts); !ok {
+		t.Errorf("unexpected diffs: %+v", diffs)
+	}
+}
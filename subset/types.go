@@ -0,0 +1,71 @@
+package subset
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// typePrefix starts a subset string asserting only the superset's type,
+// such as "$type:string"
+const typePrefix = "$type:"
+
+// jsonTypes are the names accepted after typePrefix
+var jsonTypes = map[string]bool{
+	"null": true, "boolean": true, "string": true, "number": true, "object": true, "array": true,
+}
+
+// jsonType returns the JSON type name of a decoded value
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	}
+	if _, ok := toFloat(v); ok {
+		return "number"
+	}
+	if reflect.ValueOf(v).Kind() == reflect.Map {
+		return "object"
+	}
+	return "unknown"
+}
+
+// checkTypeToken handles a subset string like "$type:number", requiring the
+// superset value to have that JSON type. handled is false for other values.
+func checkTypeToken(subset, superset interface{}, path spec.NormalizedPath) (ok bool, diffs []Diff, handled bool) {
+	s, isString := subset.(string)
+	if !isString || !strings.HasPrefix(s, typePrefix) {
+		return false, nil, false
+	}
+
+	want := strings.TrimPrefix(s, typePrefix)
+	if !jsonTypes[want] {
+		return false, []Diff{{
+			Path:          copyPath(path),
+			Type:          DiffValueMismatch,
+			SubsetValue:   subset,
+			SupersetValue: superset,
+			Message:       fmt.Sprintf("unknown type %q (want null, boolean, string, number, object or array)", want),
+		}}, true
+	}
+	if got := jsonType(superset); got != want {
+		return false, []Diff{{
+			Path:          copyPath(path),
+			Type:          DiffTypeMismatch,
+			SubsetValue:   subset,
+			SupersetValue: superset,
+			Message:       fmt.Sprintf("want %s, got %s", want, got),
+		}}, true
+	}
+	return true, nil, true
+}
@@ -0,0 +1,61 @@
+package subset
+
+import "testing"
+
+func TestTypeTokens(t *testing.T) {
+	superset := map[string]interface{}{
+		"s": "text",
+		"n": float64(1),
+		"b": true,
+		"z": nil,
+		"o": map[string]interface{}{"k": "v"},
+		"a": []interface{}{float64(1)},
+	}
+
+	tests := []struct {
+		token string
+		key   string
+		want  bool
+	}{
+		{"$type:string", "s", true},
+		{"$type:string", "n", false},
+		{"$type:number", "n", true},
+		{"$type:number", "s", false},
+		{"$type:boolean", "b", true},
+		{"$type:boolean", "z", false},
+		{"$type:null", "z", true},
+		{"$type:null", "b", false},
+		{"$type:object", "o", true},
+		{"$type:object", "a", false},
+		{"$type:array", "a", true},
+		{"$type:array", "o", false},
+	}
+
+	opts := Options{EnableMatchers: true}
+	for _, tt := range tests {
+		t.Run(tt.token+" "+tt.key, func(t *testing.T) {
+			subset := map[string]interface{}{tt.key: tt.token}
+			got, diffs := CheckSubsetWithOptions(subset, superset, opts)
+			if got != tt.want {
+				t.Fatalf("CheckSubsetWithOptions() = %v, want %v; diffs: %+v", got, tt.want, diffs)
+			}
+			if !tt.want && (len(diffs) != 1 || diffs[0].Type != DiffTypeMismatch) {
+				t.Errorf("diffs = %+v, want one type mismatch", diffs)
+			}
+		})
+	}
+}
+
+func TestTypeTokenErrors(t *testing.T) {
+	subset := map[string]interface{}{"a": "$type:integer"}
+	superset := map[string]interface{}{"a": float64(1)}
+
+	ok, diffs := CheckSubsetWithOptions(subset, superset, Options{EnableMatchers: true})
+	if ok || len(diffs) != 1 || diffs[0].Type != DiffValueMismatch {
+		t.Errorf("unknown type should fail with a value mismatch, got %v %+v", ok, diffs)
+	}
+
+	if ok, _ := CheckSubset(map[string]interface{}{"a": "$type:number"}, superset); ok {
+		t.Error("type tokens should be literal strings without EnableMatchers")
+	}
+}
@@ -0,0 +1,159 @@
+package subset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/theory/jsonpath/spec"
+)
+
+// unifiedContext is the number of unchanged lines shown around each change
+const unifiedContext = 3
+
+// unifiedLine is one line of a unified diff: ' ', '-' or '+' and its text
+type unifiedLine struct {
+	op      byte
+	content string
+}
+
+// FormatDiffUnified renders the differences as a unified diff (diff -u)
+// that turns the superset's view of the subset into the subset. Lines
+// prefixed with "-" show what the superset has, lines prefixed with "+"
+// show what the subset expects; missing keys and elements are "+" only.
+// Extra keys are not shown. It returns "" when there is nothing to change.
+func FormatDiffUnified(subset interface{}, diffs []Diff, supersetName, subsetName string) string {
+	var blocks []spec.NormalizedPath
+	replaced := make(map[string]bool)
+	old := subset
+	for _, d := range diffs {
+		switch d.Type {
+		case DiffValueMismatch, DiffTypeMismatch, DiffArrayLengthMismatch:
+			old = insertValue(old, d.Path, d.SupersetValue)
+			replaced[d.Path.String()] = true
+		case DiffMissingKey, DiffElementNotFound:
+		default:
+			continue
+		}
+		blocks = append(blocks, d.Path)
+	}
+
+	newLines := generateLines(subset, spec.NormalizedPath{}, 0, nil)
+	oldLines := generateLines(old, spec.NormalizedPath{}, 0, nil)
+
+	var entries []unifiedLine
+	for i := 0; i < len(newLines); {
+		block, ok := enclosingPath(newLines[i].Path, blocks)
+		if !ok {
+			entries = append(entries, unifiedLine{' ', newLines[i].Content})
+			i++
+			continue
+		}
+		// The changed value is replaced as a whole, even if diffs nested
+		// inside it were reported as well.
+		for _, line := range oldLines {
+			if replaced[block.String()] && hasPathPrefix(line.Path, block) {
+				entries = append(entries, unifiedLine{'-', line.Content})
+			}
+		}
+		for ; i < len(newLines) && hasPathPrefix(newLines[i].Path, block); i++ {
+			entries = append(entries, unifiedLine{'+', newLines[i].Content})
+		}
+	}
+
+	hunks := unifiedHunks(entries)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", supersetName, subsetName)
+	for _, h := range hunks {
+		sb.WriteString(h)
+	}
+	return sb.String()
+}
+
+// unifiedHunks groups changed lines with their context into "@@" hunks
+func unifiedHunks(entries []unifiedLine) []string {
+	// oldBefore[i] and newBefore[i] count the lines preceding entry i on each side
+	oldBefore := make([]int, len(entries)+1)
+	newBefore := make([]int, len(entries)+1)
+	for i, e := range entries {
+		oldBefore[i+1], newBefore[i+1] = oldBefore[i], newBefore[i]
+		if e.op != '+' {
+			oldBefore[i+1]++
+		}
+		if e.op != '-' {
+			newBefore[i+1]++
+		}
+	}
+
+	var hunks []string
+	for i := 0; i < len(entries); {
+		if entries[i].op == ' ' {
+			i++
+			continue
+		}
+
+		start := max(i-unifiedContext, 0)
+		end := i
+		// Extend the hunk while the next change is close enough to share context.
+		for j := i; j < len(entries); j++ {
+			if entries[j].op != ' ' {
+				end = j + 1
+			} else if j-end >= 2*unifiedContext {
+				break
+			}
+		}
+		end = min(end+unifiedContext, len(entries))
+
+		var sb strings.Builder
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n",
+			unifiedRange(oldBefore[start], oldBefore[end]-oldBefore[start]),
+			unifiedRange(newBefore[start], newBefore[end]-newBefore[start]))
+		for _, e := range entries[start:end] {
+			sb.WriteByte(e.op)
+			sb.WriteString(e.content)
+			sb.WriteByte('\n')
+		}
+		hunks = append(hunks, sb.String())
+		i = end
+	}
+	return hunks
+}
+
+// unifiedRange formats a hunk range the way diff -u does: "start,count",
+// or just "start" for a single line. An empty range names the line before it.
+func unifiedRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
+
+// enclosingPath returns the first of paths that path lies within
+func enclosingPath(path spec.NormalizedPath, paths []spec.NormalizedPath) (spec.NormalizedPath, bool) {
+	for _, p := range paths {
+		if hasPathPrefix(path, p) {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// hasPathPrefix reports whether path equals prefix or lies below it
+func hasPathPrefix(path, prefix spec.NormalizedPath) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,76 @@
+package subset
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatDiffUnifiedGolden(t *testing.T) {
+	var sub, super interface{}
+	if err := json.Unmarshal([]byte(`{
+		"name": "myapp", "license": "MIT", "version": "1.0.0",
+		"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6, "g": 7, "h": 8,
+		"tags": ["x", "y"],
+		"user": {"id": 1, "role": "admin"}
+	}`), &sub); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(`{
+		"name": "other", "version": "1.0.0",
+		"a": 1, "b": 2, "c": 3, "d": 4, "e": 5, "f": 6, "g": 7, "h": 8,
+		"tags": ["x"],
+		"user": {"id": 1, "role": ["user"]}
+	}`), &super); err != nil {
+		t.Fatal(err)
+	}
+
+	_, diffs := CheckSubset(sub, super)
+	got := FormatDiffUnified(sub, diffs, "superset.json", "subset.json")
+
+	want, err := os.ReadFile(filepath.Join("testdata", "unified.golden"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != string(want) {
+		t.Errorf("FormatDiffUnified() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestFormatDiffUnifiedHunks(t *testing.T) {
+	sub := map[string]interface{}{}
+	super := map[string]interface{}{}
+	for _, k := range []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j"} {
+		sub[k] = k
+		super[k] = k
+	}
+	sub["a"], sub["j"] = "x", "y"
+
+	_, diffs := CheckSubset(sub, super)
+	got := FormatDiffUnified(sub, diffs, "old", "new")
+	want := `--- old
++++ new
+@@ -1,5 +1,5 @@
+ {
+-  "a": "a",
++  "a": "x",
+   "b": "b",
+   "c": "c",
+   "d": "d",
+@@ -8,5 +8,5 @@
+   "g": "g",
+   "h": "h",
+   "i": "i",
+-  "j": "j"
++  "j": "y"
+ }
+`
+	if got != want {
+		t.Errorf("FormatDiffUnified() =\n%s\nwant\n%s", got, want)
+	}
+
+	if got := FormatDiffUnified(super, nil, "old", "new"); got != "" {
+		t.Errorf("no diffs should render nothing, got %q", got)
+	}
+}
@@ -0,0 +1,51 @@
+package subset
+
+// WalkDiffs calls fn with each difference between subset and superset
+// using the default options, as the comparison finds it. It stops as soon
+// as fn returns false.
+func WalkDiffs(subset, superset interface{}, fn func(Diff) bool) {
+	WalkDiffsWithOptions(subset, superset, Options{}, fn)
+}
+
+// WalkDiffsWithOptions is like WalkDiffs but with options. fn receives the
+// same diffs CheckSubsetWithOptions returns, in the same order; Parallel
+// is ignored so that fn is never called concurrently.
+func WalkDiffsWithOptions(subset, superset interface{}, opts Options, fn func(Diff) bool) {
+	opts.stream = &diffStream{fn: fn}
+	opts.Parallel = 0
+	_, diffs := CheckSubsetWithOptions(subset, superset, opts)
+	// Required key diffs are added after the recursion.
+	opts.stream.emit(diffs)
+}
+
+// diffStream passes diffs to a WalkDiffs callback once they are final,
+// that is when checkSubsetPath returns them outside of a trial comparison
+// whose diffs are discarded. Loops that mix diffs of their own with those
+// of their children emit as they go, so the order is that of the returned
+// slice. A nil stream does nothing.
+type diffStream struct {
+	fn      func(Diff) bool
+	stopped bool
+}
+
+// emit calls fn with each diff not emitted yet and marks it, so that the
+// callers the diffs are passed up to skip it
+func (s *diffStream) emit(diffs []Diff) {
+	if s == nil {
+		return
+	}
+	for i := range diffs {
+		if diffs[i].emitted {
+			continue
+		}
+		if !s.stopped && !s.fn(diffs[i]) {
+			s.stopped = true
+		}
+		diffs[i].emitted = true
+	}
+}
+
+// done reports whether the callback asked to stop
+func (s *diffStream) done() bool {
+	return s != nil && s.stopped
+}
@@ -0,0 +1,91 @@
+package subset
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWalkDiffs(t *testing.T) {
+	subset := map[string]interface{}{
+		"name":    "alice",
+		"email":   "alice@example.com",
+		"tags":    []interface{}{"admin", "ops"},
+		"users":   []interface{}{map[string]interface{}{"id": float64(1), "role": "admin"}},
+		"payload": `{"id": 7}`,
+		"nested":  map[string]interface{}{"a": float64(1), "b": float64(2)},
+	}
+	superset := map[string]interface{}{
+		"name":    "bob",
+		"tags":    []interface{}{"admin"},
+		"users":   []interface{}{map[string]interface{}{"id": float64(1), "role": "user"}},
+		"payload": `{"id": 8}`,
+		"nested":  map[string]interface{}{"a": float64(0), "b": float64(2), "c": float64(3)},
+		"extra":   true,
+	}
+	p, _ := ParsePathPattern("payload")
+	base := Options{ShowExtra: true, ParseEmbedded: []PathPattern{p}, RequiredKeys: []string{"id"}}
+	keyed, ordered, parallel := base, base, base
+	keyed.ArrayKey = "id"
+	ordered.ArrayOrder = ArrayOrdered
+	parallel.Parallel = 4
+
+	for _, opts := range []Options{base, keyed, ordered, parallel} {
+		_, want := CheckSubsetWithOptions(subset, superset, opts)
+		var got []Diff
+		WalkDiffsWithOptions(subset, superset, opts, func(d Diff) bool {
+			got = append(got, d)
+			return true
+		})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("WalkDiffsWithOptions() passed\n%+v\nwant\n%+v", got, want)
+		}
+		if len(want) < 7 {
+			t.Fatalf("the test documents should produce several diffs, got %d", len(want))
+		}
+	}
+}
+
+func TestWalkDiffsArrayLength(t *testing.T) {
+	subset := []interface{}{float64(1), float64(2)}
+	superset := []interface{}{float64(9)}
+	opts := Options{ArrayOrder: ArrayOrdered, ArrayExactLength: true}
+
+	_, want := CheckSubsetWithOptions(subset, superset, opts)
+	var got []Diff
+	WalkDiffsWithOptions(subset, superset, opts, func(d Diff) bool {
+		got = append(got, d)
+		return true
+	})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WalkDiffsWithOptions() passed\n%+v\nwant\n%+v", got, want)
+	}
+	if len(want) == 0 || want[0].Type != DiffArrayLengthMismatch {
+		t.Errorf("first diff = %+v, want the array length mismatch", want)
+	}
+}
+
+func TestWalkDiffsStops(t *testing.T) {
+	subset, superset := branchDocs(30)
+
+	var full Stats
+	CheckSubsetWithOptions(subset, superset, Options{Stats: &full})
+
+	var stats Stats
+	calls := 0
+	WalkDiffsWithOptions(subset, superset, Options{Stats: &stats}, func(Diff) bool {
+		calls++
+		return false
+	})
+	if calls != 1 {
+		t.Errorf("callback called %d times after returning false, want 1", calls)
+	}
+	if stats.ObjectKeys >= full.ObjectKeys {
+		t.Errorf("compared %d keys after stopping, want fewer than the full %d", stats.ObjectKeys, full.ObjectKeys)
+	}
+
+	// Without differences, the callback is never called.
+	WalkDiffs(subset, subset, func(Diff) bool {
+		t.Error("callback called for identical documents")
+		return true
+	})
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/zinrai/json-subset/subset"
+)
+
+// runSubsets checks each subset file against one superset, which is
+// loaded once. Every subset must be contained for the run to pass. In
+// quiet mode only load errors are printed.
+func runSubsets(subsetFiles []string, supersetFile string, in inputOptions, at string, opts subset.Options, formatOpts subset.FormatOptions, quiet bool, stdout, stderr io.Writer) int {
+	report, reportErr := stdout, stderr
+	if quiet {
+		report, reportErr = io.Discard, io.Discard
+	}
+
+	supersetData, err := loadInput(supersetFile, in)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error loading %s: %v\n", supersetFile, err)
+		return loadExitCode(err)
+	}
+	if at != "" {
+		supersetData, _, err = subset.LocateNode(supersetData, at)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error selecting --at in %s: %v\n", supersetFile, err)
+			return exitError
+		}
+	}
+
+	passed, failed := 0, 0
+	for _, subsetFile := range subsetFiles {
+		subsetData, err := loadInput(subsetFile, in)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error loading %s: %v\n", subsetFile, err)
+			return loadExitCode(err)
+		}
+
+		isSubset, diffs := subset.CheckSubsetWithOptions(subsetData, supersetData, opts)
+		if isSubset {
+			passed++
+			fmt.Fprintf(report, "PASS: %s\n", subsetFile)
+			continue
+		}
+		failed++
+		fmt.Fprintf(reportErr, "FAIL: %s\n", subsetFile)
+		fmt.Fprint(reportErr, subset.FormatDiffOutputWithOptions(subsetData, diffs, formatOpts))
+		fmt.Fprintln(reportErr, subset.FormatDiffSummary(diffs))
+	}
+
+	fmt.Fprintf(reportErr, "%d passed, %d failed\n", passed, failed)
+	if failed > 0 {
+		return exitFailure
+	}
+	return exitSuccess
+}